@@ -1,14 +1,20 @@
 package main
 
 import (
+	"context"
 	"github.com/Kosodaka/enricher-service/internal/adapters/app/app"
 	"github.com/Kosodaka/enricher-service/internal/adapters/app/router"
 	"github.com/Kosodaka/enricher-service/internal/adapters/enricher"
+	"github.com/Kosodaka/enricher-service/internal/adapters/enricher/cache"
+	"github.com/Kosodaka/enricher-service/internal/adapters/enricher/coalesce"
+	"github.com/Kosodaka/enricher-service/internal/adapters/enricher/rules"
 	"github.com/Kosodaka/enricher-service/internal/adapters/repository"
 	"github.com/Kosodaka/enricher-service/internal/adapters/repository/postgres"
+	"github.com/Kosodaka/enricher-service/internal/adapters/worker"
 	"github.com/Kosodaka/enricher-service/internal/domain/service"
 	"github.com/Kosodaka/enricher-service/pkg/config"
 	"github.com/Kosodaka/enricher-service/pkg/logger"
+	"github.com/Kosodaka/enricher-service/pkg/policy"
 	"github.com/Kosodaka/enricher-service/pkg/validator"
 	"log/slog"
 )
@@ -22,16 +28,50 @@ func main() {
 	logger := logger.SetupLogger(cfg.GetEnv())
 	valid := validator.NewValidator()
 	logger.Info("start", slog.String("env", cfg.Env))
-	enricher := enricher.NewEnricher(cfg)
 	psql := postgres.NewPsql(cfg.PostgresDSN)
 	db, err := psql.GetDb()
 	if err != nil {
 		panic(err)
 	}
+	enrichmentCache := repository.NewEnrichmentCachePostgres(db)
+	var enricherCfg enricher.Configer = cfg
+	if cfg.GetDatasetFallback() {
+		enricherCfg = enricher.WithFallback(cfg, enricher.ProviderDataset)
+	}
+	httpEnricher, err := enricher.NewEnricher(enricherCfg)
+	if err != nil {
+		panic(err)
+	}
+	reviewPolicy, err := policy.Parse(cfg.GetReviewPolicy())
+	if err != nil {
+		panic(err)
+	}
+	cachedEnricher := cache.NewEnricher(httpEnricher, enrichmentCache, cfg)
+	enricher := coalesce.NewEnricher(cachedEnricher)
 
 	personRepository := repository.NewPersonPostgres(db)
+	jobRepository := repository.NewJobPostgres(db, cfg.GetEnrichJobLease())
 	personService := service.NewService()
-	personService.Init(service.SetRepository(personRepository), service.SetEnricher(enricher), service.SetLogger(logger), service.SetValidator(valid))
+	personService.Init(service.SetRepository(personRepository), service.SetEnricher(enricher), service.SetReenricher(cachedEnricher.Refresher()), service.SetStatusReporter(httpEnricher), service.SetQuotaReporter(httpEnricher), service.SetCoalescingReporter(enricher), service.SetPartialEnrichment(cfg.GetPartialEnrichment()), service.SetDefaultCountryId(cfg.GetDefaultCountryId()), service.SetNameRules(rules.NewRules(), cfg.GetNameRulesConfidence()), service.SetLogger(logger), service.SetValidator(valid),
+		service.SetJobRepository(jobRepository), service.SetJobAttempts(cfg.GetEnrichJobAttempts()),
+		service.SetEnrichmentLogRepository(repository.NewEnrichmentLogPostgres(db)), service.SetReviewPolicy(reviewPolicy))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	usageRepository := repository.NewProviderUsagePostgres(db)
+	if err := httpEnricher.LoadUsage(ctx, usageRepository); err != nil {
+		logger.Error("failed to load provider usage", slog.Any("error", err))
+	}
+	go httpEnricher.KeepUsage(ctx, usageRepository, cfg.GetUsageSaveInterval(), logger)
+	go worker.NewPool(personService, cfg, logger).Run(ctx)
+	if retention := cfg.GetEnrichLogRetention(); retention > 0 {
+		go worker.NewPruner("enrichment log", personService.PruneEnrichmentLog, retention, cfg.GetPruneInterval(), logger).Run(ctx)
+	}
+	if retention := cfg.GetDeletedRetention(); retention > 0 {
+		go worker.NewPruner("deleted persons", personService.PurgePersons, retention, cfg.GetPruneInterval(), logger).Run(ctx)
+	}
+
 	personRouter := app.NewPersonRouter(personService)
 	app := router.NewRouter(cfg, personRouter)
 	if err := app.Run(); err != nil {
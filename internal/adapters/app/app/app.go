@@ -1,15 +1,21 @@
 package app
 
 import (
+	"errors"
 	"fmt"
 	"github.com/Kosodaka/enricher-service/internal/adapters/app/response"
 	"github.com/Kosodaka/enricher-service/internal/adapters/app/service"
 	"github.com/Kosodaka/enricher-service/internal/domain/dto"
+	domainErr "github.com/Kosodaka/enricher-service/internal/domain/errors"
 	"github.com/Kosodaka/enricher-service/internal/domain/model"
+	"github.com/Kosodaka/enricher-service/internal/domain/ports/repository"
 	"github.com/gin-gonic/gin"
 	"log"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type PersonRouter struct {
@@ -41,15 +47,88 @@ func (r *PersonRouter) GetPerson(c *gin.Context) {
 		return
 	}
 
-	person, err := r.service.GetPerson(c.Request.Context(), id)
+	includeDeleted, _ := strconv.ParseBool(c.Query("include_deleted"))
+	if asOf := c.Query("as_of"); asOf != "" {
+		r.getPersonAsOf(c, op, id, asOf, includeDeleted)
+		return
+	}
+	person, err := r.service.GetPerson(c.Request.Context(), id, includeDeleted)
 	if err != nil {
-		response.NewErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("%s : failed to get person in service", err))
+		status := http.StatusBadRequest
+		if errors.Is(err, domainErr.ErrPersonNotFound) {
+			status = http.StatusNotFound
+		}
+		response.NewErrorResponse(c, status, fmt.Sprintf("%s : failed to get person in service", err))
 		log.Print(op, " :failed to get person in service")
 		return
 	}
+	c.Header("ETag", etag(person.Version))
+	c.JSON(http.StatusOK, person)
+}
+
+// getPersonAsOf responds with the person as it was at the RFC 3339 time asOf. A past
+// state cannot be updated, so it comes without an ETag.
+func (r *PersonRouter) getPersonAsOf(c *gin.Context, op string, id int, asOf string, includeDeleted bool) {
+	at, err := time.Parse(time.RFC3339, asOf)
+	if err != nil {
+		response.NewErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("%s : invalid as of", err))
+		log.Print(op, " :invalid as of")
+		return
+	}
+	person, err := r.service.GetPersonAsOf(c.Request.Context(), id, at, includeDeleted)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, domainErr.ErrPersonNotFound) {
+			status = http.StatusNotFound
+		}
+		response.NewErrorResponse(c, status, fmt.Sprintf("%s : failed to reconstruct person", err))
+		log.Print(op, " :failed to reconstruct person")
+		return
+	}
 	c.JSON(http.StatusOK, person)
 }
 
+// GetPersonHistory returns the recorded changes of the person, the oldest first.
+func (r *PersonRouter) GetPersonHistory(c *gin.Context) {
+	op := "app.GetPersonHistory"
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.NewErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("%s : invalid id", err))
+		log.Print(op, " :invalid id")
+		return
+	}
+
+	history, err := r.service.GetPersonHistory(c.Request.Context(), id)
+	if err != nil {
+		response.NewErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("%s : failed to get person history", err))
+		log.Print(op, " :failed to get person history")
+		return
+	}
+	c.JSON(http.StatusOK, history)
+}
+
+// etag is the entity tag of a person version.
+func etag(version int64) string {
+	return strconv.Quote(strconv.FormatInt(version, 10))
+}
+
+// matchVersion parses the person version from an If-Match header holding a single entity tag.
+// A weak tag names the same version, since proxies weaken tags when they compress responses,
+// and * matches any version.
+func matchVersion(header string) (int64, error) {
+	header = strings.TrimSpace(header)
+	if header == "*" {
+		return repository.AnyVersion, nil
+	}
+	tag, err := strconv.Unquote(strings.TrimPrefix(header, "W/"))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(tag, 10, 64)
+}
+
+// UpdatePerson saves the person if it is still at the version given by If-Match or the
+// version field, so that concurrent editors do not overwrite each other.
 func (r *PersonRouter) UpdatePerson(c *gin.Context) {
 	op := "app.UpdatePerson"
 	request := &model.Person{}
@@ -58,14 +137,37 @@ func (r *PersonRouter) UpdatePerson(c *gin.Context) {
 		log.Print(op, " :failed to update person")
 		return
 	}
+	if ifMatch := c.GetHeader("If-Match"); ifMatch != "" {
+		version, err := matchVersion(ifMatch)
+		if err != nil {
+			response.NewErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("%s : invalid If-Match", ifMatch))
+			log.Print(op, " :invalid If-Match")
+			return
+		}
+		request.Version = version
+	}
+	if request.Version == 0 {
+		response.NewErrorResponse(c, http.StatusPreconditionRequired, "version is required : send If-Match or version")
+		log.Print(op, " :no version")
+		return
+	}
 
 	err := r.service.UpdatePerson(c.Request.Context(), request)
 	if err != nil {
-		response.NewErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("%s: failed to update person in service", err))
+		status := http.StatusBadRequest
+		var conflict *repository.ErrVersionConflict
+		switch {
+		case errors.As(err, &conflict):
+			status = http.StatusPreconditionFailed
+		case errors.Is(err, domainErr.ErrPersonNotFound):
+			status = http.StatusNotFound
+		}
+		response.NewErrorResponse(c, status, fmt.Sprintf("%s: failed to update person in service", err))
 		log.Print(op, " :failed to update person in service")
 		return
 	}
-	c.JSON(http.StatusOK, response.StatusResponse{"ok"})
+	c.Header("ETag", etag(request.Version))
+	c.JSON(http.StatusOK, response.StatusResponse{Status: "ok"})
 }
 
 func (r *PersonRouter) DeletePerson(c *gin.Context) {
@@ -79,45 +181,163 @@ func (r *PersonRouter) DeletePerson(c *gin.Context) {
 
 	err := r.service.DeletePerson(c.Request.Context(), request.Id)
 	if err != nil {
-		response.NewErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("%s : failed to delete person in service", err))
+		status := http.StatusBadRequest
+		if errors.Is(err, domainErr.ErrPersonNotFound) {
+			status = http.StatusNotFound
+		}
+		response.NewErrorResponse(c, status, fmt.Sprintf("%s : failed to delete person in service", err))
 		log.Print(op, " :failed to delete person")
 		return
 	}
-	c.JSON(http.StatusOK, response.StatusResponse{"ok"})
+	c.JSON(http.StatusOK, response.StatusResponse{Status: "ok"})
+}
+
+// RestorePerson brings back a deleted person that was not purged yet.
+func (r *PersonRouter) RestorePerson(c *gin.Context) {
+	op := "app.RestorePerson"
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.NewErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("%s : invalid id", err))
+		log.Print(op, " :invalid id")
+		return
+	}
+
+	person, err := r.service.RestorePerson(c.Request.Context(), id)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, domainErr.ErrPersonNotFound) {
+			status = http.StatusNotFound
+		}
+		response.NewErrorResponse(c, status, fmt.Sprintf("%s : failed to restore person", err))
+		log.Print(op, " :failed to restore person")
+		return
+	}
+	c.JSON(http.StatusOK, person)
 }
 
 func (r *PersonRouter) GetPersons(c *gin.Context) {
 	op := "app.GetPersons"
-	data := &model.Person{}
-	data.Name = c.Query("name")
-	data.Surname = c.Query("surname")
-	data.Patronymic = c.Query("patronymic")
-	ageStr := c.Query("age")
-	if ageStr != "" {
-		ageInt, err := strconv.Atoi(ageStr)
-		if err != nil {
-			response.NewErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("%s : invalid age", err))
-			log.Print(op, " :invalid age")
-			return
-		}
-		if ageInt < 0 {
-			response.NewErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("%s : invalid age", err))
-			log.Print(op, " :invalid age")
-			return
-		}
-		data.Age = ageInt
+	data, ok := bindPersonFilter(c, op)
+	if !ok {
+		return
 	}
 
-	data.Gender = c.Query("gender")
-	data.Nationality = c.Query("nationality")
+	if !bindPage(c, op, data) {
+		return
+	}
 
-	persons, err := r.service.GetPersons(c.Request.Context(), data)
+	page, err := r.service.GetPersons(c.Request.Context(), data)
 	if err != nil {
 		response.NewErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("%s : failed to get persons", err))
 		log.Print(op, " :failed to get persons")
 		return
 	}
-	c.JSON(http.StatusOK, persons)
+	c.JSON(http.StatusOK, page)
+}
+
+// SearchPersons finds persons by the approximate full name in q, ranked by similarity.
+func (r *PersonRouter) SearchPersons(c *gin.Context) {
+	op := "app.SearchPersons"
+	limit := 0
+	if limitStr := c.Query("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > dto.MaxSearchLimit {
+			response.NewErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("%s : invalid limit", limitStr))
+			log.Print(op, " :invalid limit")
+			return
+		}
+	}
+
+	includeDeleted, _ := strconv.ParseBool(c.Query("include_deleted"))
+	matches, err := r.service.SearchPersons(c.Request.Context(), c.Query("q"), limit, includeDeleted)
+	if err != nil {
+		response.NewErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("%s : failed to search persons", err))
+		log.Print(op, " :failed to search persons")
+		return
+	}
+	c.JSON(http.StatusOK, matches)
+}
+
+// bindPage parses the sort order, the page size, the cursor and whether deleted persons
+// are listed, e.g. sort=surname,-age orders by surname and then by age descending.
+func bindPage(c *gin.Context, op string, data *dto.PersonFilter) bool {
+	if sort := c.Query("sort"); sort != "" {
+		for _, field := range strings.Split(sort, ",") {
+			field = strings.TrimSpace(field)
+			sortField := dto.SortField{Field: strings.TrimPrefix(field, "-"), Desc: strings.HasPrefix(field, "-")}
+			if !slices.Contains(dto.PersonSortFields, sortField.Field) {
+				response.NewErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("%s : invalid sort field", field))
+				log.Print(op, " :invalid sort field")
+				return false
+			}
+			data.Sort = append(data.Sort, sortField)
+		}
+	}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > dto.MaxPersonLimit {
+			response.NewErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("%s : invalid limit", limitStr))
+			log.Print(op, " :invalid limit")
+			return false
+		}
+		data.Limit = limit
+	}
+	data.Cursor = c.Query("cursor")
+	data.IncludeDeleted, _ = strconv.ParseBool(c.Query("include_deleted"))
+	return true
+}
+
+// bindPersonFilter parses the person filter from the query. It reports false after
+// responding with the error when the query is invalid.
+func bindPersonFilter(c *gin.Context, op string) (*dto.PersonFilter, bool) {
+	data := &dto.PersonFilter{}
+	data.Name = c.Query("name")
+	data.Surname = c.Query("surname")
+	data.Patronymic = c.Query("patronymic")
+	ages := []struct {
+		key string
+		age **int
+	}{{"age", &data.Age}, {"age_gte", &data.AgeGte}, {"age_lte", &data.AgeLte}}
+	for _, a := range ages {
+		key, age := a.key, a.age
+		ageStr := c.Query(key)
+		if ageStr == "" {
+			continue
+		}
+		ageInt, err := strconv.Atoi(ageStr)
+		if err != nil || ageInt < 0 {
+			response.NewErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("%s : invalid %s", ageStr, key))
+			log.Print(op, " :invalid ", key)
+			return nil, false
+		}
+		*age = &ageInt
+	}
+
+	if gender := c.Query("gender"); gender != "" {
+		data.Gender = &gender
+	}
+	if nationality := c.Query("nationality"); nationality != "" {
+		for _, country := range strings.Split(nationality, ",") {
+			data.Nationalities = append(data.Nationalities, strings.ToUpper(strings.TrimSpace(country)))
+		}
+	}
+	data.NationalityMode = c.DefaultQuery("nationality_mode", dto.NationalityModeTop)
+	if data.NationalityMode != dto.NationalityModeTop && data.NationalityMode != dto.NationalityModeAny {
+		response.NewErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("%s : invalid nationality mode", data.NationalityMode))
+		log.Print(op, " :invalid nationality mode")
+		return nil, false
+	}
+	if probabilityStr := c.Query("min_probability"); probabilityStr != "" {
+		probability, err := strconv.ParseFloat(probabilityStr, 64)
+		if err != nil || probability < 0 || probability > 1 {
+			response.NewErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("%s : invalid min probability", probabilityStr))
+			log.Print(op, " :invalid min probability")
+			return nil, false
+		}
+		data.NationalityMinProbability = probability
+	}
+	return data, true
 }
 
 func (r *PersonRouter) AddPerson(c *gin.Context) {
@@ -129,9 +349,27 @@ func (r *PersonRouter) AddPerson(c *gin.Context) {
 		return
 	}
 
+	if async, _ := strconv.ParseBool(c.Query("async")); async {
+		id, jobId, err := r.service.AddPersonAsync(c.Request.Context(), &input)
+		if err != nil {
+			response.NewErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("%s: failed to queue person", err))
+			log.Print(op, " :failed to queue person")
+			return
+		}
+		c.JSON(http.StatusAccepted, map[string]interface{}{
+			"id":     id,
+			"job_id": jobId,
+		})
+		return
+	}
+
 	id, err := r.service.AddPerson(c.Request.Context(), &input)
 	if err != nil {
-		response.NewErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("%s: failed to add person to storage", err))
+		status := enrichmentStatus(err)
+		if status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable {
+			setRetryAfter(c, err)
+		}
+		response.NewErrorResponse(c, status, fmt.Sprintf("%s: failed to add person to storage", err))
 		log.Print(op, " :failed to add persons to storage")
 		return
 	}
@@ -140,3 +378,188 @@ func (r *PersonRouter) AddPerson(c *gin.Context) {
 		"id": id,
 	})
 }
+
+func (r *PersonRouter) GetJob(c *gin.Context) {
+	op := "app.GetJob"
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.NewErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("%s : invalid id", err))
+		log.Print(op, " :invalid id")
+		return
+	}
+
+	job, err := r.service.GetJob(c.Request.Context(), id)
+	if err != nil {
+		response.NewErrorResponse(c, http.StatusNotFound, fmt.Sprintf("%s : failed to get job in service", err))
+		log.Print(op, " :failed to get job in service")
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+func (r *PersonRouter) GetProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, r.service.ProviderStatuses(c.Request.Context()))
+}
+
+func (r *PersonRouter) GetProviderQuotas(c *gin.Context) {
+	c.JSON(http.StatusOK, r.service.ProviderQuotas(c.Request.Context()))
+}
+
+// GetCoalescingStats tells how many enrichment calls joined a call already in flight.
+func (r *PersonRouter) GetCoalescingStats(c *gin.Context) {
+	c.JSON(http.StatusOK, r.service.CoalescingStats(c.Request.Context()))
+}
+
+func (r *PersonRouter) ReenrichPerson(c *gin.Context) {
+	op := "app.ReenrichPerson"
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.NewErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("%s : invalid id", err))
+		log.Print(op, " :invalid id")
+		return
+	}
+
+	person, err := r.service.ReenrichPerson(c.Request.Context(), id)
+	if err != nil {
+		status := enrichmentStatus(err)
+		if errors.Is(err, domainErr.ErrPersonNotFound) {
+			status = http.StatusNotFound
+		}
+		if status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable {
+			setRetryAfter(c, err)
+		}
+		response.NewErrorResponse(c, status, fmt.Sprintf("%s: failed to re-enrich person", err))
+		log.Print(op, " :failed to re-enrich person")
+		return
+	}
+	c.JSON(http.StatusOK, person)
+}
+
+// GetEnrichmentLog returns the raw provider answers the person was enriched from.
+func (r *PersonRouter) GetEnrichmentLog(c *gin.Context) {
+	op := "app.GetEnrichmentLog"
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.NewErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("%s : invalid id", err))
+		log.Print(op, " :invalid id")
+		return
+	}
+
+	logs, err := r.service.GetEnrichmentLog(c.Request.Context(), id)
+	if err != nil {
+		response.NewErrorResponse(c, http.StatusNotFound, fmt.Sprintf("%s : failed to get enrichment log", err))
+		log.Print(op, " :failed to get enrichment log")
+		return
+	}
+	c.JSON(http.StatusOK, logs)
+}
+
+// StartReenrich re-enriches the persons matching the GET /persons filter in the background.
+// enriched_before further limits it to the persons enriched before that RFC 3339 time.
+func (r *PersonRouter) StartReenrich(c *gin.Context) {
+	op := "app.StartReenrich"
+	personFilter, ok := bindPersonFilter(c, op)
+	if !ok {
+		return
+	}
+	filter := &dto.ReenrichFilter{PersonFilter: *personFilter}
+	if before := c.Query("enriched_before"); before != "" {
+		enrichedBefore, err := time.Parse(time.RFC3339, before)
+		if err != nil {
+			response.NewErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("%s : invalid enriched before", err))
+			log.Print(op, " :invalid enriched before")
+			return
+		}
+		filter.EnrichedBefore = &enrichedBefore
+	}
+
+	run, err := r.service.StartReenrich(c.Request.Context(), filter)
+	if err != nil {
+		response.NewErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("%s : failed to start re-enrichment", err))
+		log.Print(op, " :failed to start re-enrichment")
+		return
+	}
+	c.JSON(http.StatusAccepted, run)
+}
+
+func (r *PersonRouter) GetReenrich(c *gin.Context) {
+	op := "app.GetReenrich"
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.NewErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("%s : invalid id", err))
+		log.Print(op, " :invalid id")
+		return
+	}
+
+	run, err := r.service.GetReenrich(c.Request.Context(), id)
+	if err != nil {
+		response.NewErrorResponse(c, http.StatusNotFound, fmt.Sprintf("%s : failed to get re-enrichment", err))
+		log.Print(op, " :failed to get re-enrichment")
+		return
+	}
+	c.JSON(http.StatusOK, run)
+}
+
+func (r *PersonRouter) CancelReenrich(c *gin.Context) {
+	op := "app.CancelReenrich"
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.NewErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("%s : invalid id", err))
+		log.Print(op, " :invalid id")
+		return
+	}
+
+	run, err := r.service.CancelReenrich(c.Request.Context(), id)
+	if err != nil {
+		response.NewErrorResponse(c, http.StatusNotFound, fmt.Sprintf("%s : failed to cancel re-enrichment", err))
+		log.Print(op, " :failed to cancel re-enrichment")
+		return
+	}
+	c.JSON(http.StatusOK, run)
+}
+
+func (r *PersonRouter) GetReviewQueue(c *gin.Context) {
+	op := "app.GetReviewQueue"
+	persons, err := r.service.GetReviewQueue(c.Request.Context())
+	if err != nil {
+		response.NewErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("%s : failed to get review queue", err))
+		log.Print(op, " :failed to get review queue")
+		return
+	}
+	c.JSON(http.StatusOK, persons)
+}
+
+// ReviewPerson confirms a person under review, the attributes in the body correct the predicted ones.
+func (r *PersonRouter) ReviewPerson(c *gin.Context) {
+	op := "app.ReviewPerson"
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.NewErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("%s : invalid id", err))
+		log.Print(op, " :invalid id")
+		return
+	}
+	request := &dto.ReviewDTO{}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(request); err != nil {
+			response.NewErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("%s : invalid review", err))
+			log.Print(op, " :invalid review")
+			return
+		}
+	}
+
+	person, err := r.service.ReviewPerson(c.Request.Context(), id, request)
+	if err != nil {
+		status := http.StatusBadRequest
+		var conflict *repository.ErrVersionConflict
+		switch {
+		case errors.Is(err, domainErr.ErrNotUnderReview), errors.As(err, &conflict):
+			status = http.StatusConflict
+		case errors.Is(err, domainErr.ErrPersonNotFound):
+			status = http.StatusNotFound
+		}
+		response.NewErrorResponse(c, status, fmt.Sprintf("%s : failed to review person", err))
+		log.Print(op, " :failed to review person")
+		return
+	}
+	c.JSON(http.StatusOK, person)
+}
@@ -0,0 +1,523 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"github.com/Kosodaka/enricher-service/internal/domain/dto"
+	domainErr "github.com/Kosodaka/enricher-service/internal/domain/errors"
+	"github.com/Kosodaka/enricher-service/internal/domain/model"
+	"github.com/Kosodaka/enricher-service/internal/domain/ports/enricher"
+	"github.com/Kosodaka/enricher-service/internal/domain/ports/repository"
+	mock_service "github.com/Kosodaka/enricher-service/pkg/mocks/api/service"
+	"github.com/Kosodaka/enricher-service/pkg/ptr"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPersonRouter_AddPerson(t *testing.T) {
+	cases := []struct {
+		name       string
+		err        error
+		status     int
+		retryAfter string
+	}{
+		{
+			name:   "invalid input",
+			err:    domainErr.EmptyField,
+			status: http.StatusBadRequest,
+		}, {
+			name:   "unknown name",
+			err:    domainErr.EnrichError{{Attribute: "age", Provider: "agify", Err: domainErr.ErrNameUnknown}},
+			status: http.StatusUnprocessableEntity,
+		}, {
+			name:   "bad upstream response",
+			err:    &domainErr.ProviderError{Provider: "agify", Status: http.StatusNotFound, Err: domainErr.ErrProviderBadResponse},
+			status: http.StatusBadGateway,
+		}, {
+			name: "upstream outage",
+			err: domainErr.EnrichError{
+				{Attribute: "age", Provider: "agify", Err: domainErr.ErrNameUnknown},
+				{Attribute: "gender", Provider: "genderize", Status: http.StatusServiceUnavailable, RetryAfter: 1500 * time.Millisecond, Err: domainErr.ErrProviderUnavailable},
+			},
+			status:     http.StatusServiceUnavailable,
+			retryAfter: "2",
+		}, {
+			name: "rate limited",
+			err: domainErr.EnrichError{
+				{Attribute: "gender", Provider: "genderize", Status: http.StatusServiceUnavailable, Err: domainErr.ErrProviderUnavailable},
+				{Attribute: "nationality", Provider: "nationalize", Status: http.StatusTooManyRequests, RetryAfter: time.Hour, Err: domainErr.ErrProviderRateLimited},
+			},
+			status:     http.StatusTooManyRequests,
+			retryAfter: "3600",
+		},
+	}
+	gin.SetMode(gin.TestMode)
+	for _, testCases := range cases {
+		t.Run(testCases.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			svc := mock_service.NewMockPersonService(ctrl)
+			svc.EXPECT().AddPerson(gomock.Any(), gomock.Any()).Return(0, testCases.err)
+
+			server := gin.New()
+			server.POST("/persons", NewPersonRouter(svc).AddPerson)
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/persons", bytes.NewBufferString(`{"name":"Olga","surname":"Ivanova"}`))
+			server.ServeHTTP(w, req)
+
+			if w.Code != testCases.status {
+				t.Errorf("got status %d, want %d", w.Code, testCases.status)
+			}
+			if got := w.Header().Get("Retry-After"); got != testCases.retryAfter {
+				t.Errorf("got Retry-After %q, want %q", got, testCases.retryAfter)
+			}
+		})
+	}
+}
+
+func TestPersonRouter_StartReenrich(t *testing.T) {
+	cases := []struct {
+		name   string
+		query  string
+		want   *dto.ReenrichFilter
+		status int
+	}{
+		{
+			name:   "filter and enriched before",
+			query:  "?nationality=RU&enriched_before=2024-03-01T00:00:00Z",
+			want:   reenrichFilter("RU", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)),
+			status: http.StatusAccepted,
+		}, {
+			name:   "invalid enriched before",
+			query:  "?enriched_before=yesterday",
+			status: http.StatusBadRequest,
+		},
+	}
+	gin.SetMode(gin.TestMode)
+	for _, testCases := range cases {
+		t.Run(testCases.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			svc := mock_service.NewMockPersonService(ctrl)
+			if testCases.want != nil {
+				svc.EXPECT().StartReenrich(gomock.Any(), testCases.want).Return(&model.ReenrichRun{Id: 1}, nil)
+			}
+
+			server := gin.New()
+			server.POST("/admin/reenrich", NewPersonRouter(svc).StartReenrich)
+			w := httptest.NewRecorder()
+			server.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/reenrich"+testCases.query, nil))
+
+			if w.Code != testCases.status {
+				t.Errorf("got status %d, want %d", w.Code, testCases.status)
+			}
+		})
+	}
+}
+
+func TestPersonRouter_GetPersons(t *testing.T) {
+	cases := []struct {
+		name   string
+		query  string
+		want   *dto.PersonFilter
+		status int
+	}{
+		{
+			name:  "filters sort and page",
+			query: "?nationality=ru,%20UA&age_gte=20&age_lte=40&sort=surname,-age&limit=10&cursor=abc",
+			want: &dto.PersonFilter{
+				NationalityMode: dto.NationalityModeTop,
+				Nationalities:   []string{"RU", "UA"},
+				AgeGte:          ptr.To(20),
+				AgeLte:          ptr.To(40),
+				Sort:            []dto.SortField{{Field: "surname"}, {Field: "age", Desc: true}},
+				Limit:           10,
+				Cursor:          "abc",
+			},
+			status: http.StatusOK,
+		}, {
+			name:   "unknown sort field",
+			query:  "?sort=status",
+			status: http.StatusBadRequest,
+		}, {
+			name:   "limit above max",
+			query:  "?limit=1001",
+			status: http.StatusBadRequest,
+		}, {
+			name:   "invalid age range bound",
+			query:  "?age_gte=-1",
+			status: http.StatusBadRequest,
+		},
+	}
+	gin.SetMode(gin.TestMode)
+	for _, testCases := range cases {
+		t.Run(testCases.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			svc := mock_service.NewMockPersonService(ctrl)
+			if testCases.want != nil {
+				svc.EXPECT().GetPersons(gomock.Any(), testCases.want).Return(&dto.PersonPage{NextCursor: "next"}, nil)
+			}
+
+			server := gin.New()
+			server.GET("/persons", NewPersonRouter(svc).GetPersons)
+			w := httptest.NewRecorder()
+			server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/persons"+testCases.query, nil))
+
+			if w.Code != testCases.status {
+				t.Errorf("got status %d, want %d", w.Code, testCases.status)
+			}
+			if testCases.want != nil && !strings.Contains(w.Body.String(), `"next_cursor":"next"`) {
+				t.Errorf("got body %s, want the next cursor", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestPersonRouter_SoftDelete(t *testing.T) {
+	cases := []struct {
+		name        string
+		method      string
+		path        string
+		body        string
+		preparation func(svc *mock_service.MockPersonService)
+		status      int
+	}{
+		{
+			name:   "delete missing person",
+			method: http.MethodDelete,
+			path:   "/person",
+			body:   `{"id":"404"}`,
+			preparation: func(svc *mock_service.MockPersonService) {
+				svc.EXPECT().DeletePerson(gomock.Any(), 404).Return(domainErr.ErrPersonNotFound)
+			},
+			status: http.StatusNotFound,
+		}, {
+			name:   "restore deleted person",
+			method: http.MethodPost,
+			path:   "/person/3/restore",
+			preparation: func(svc *mock_service.MockPersonService) {
+				svc.EXPECT().RestorePerson(gomock.Any(), 3).Return(&model.Person{Id: 3}, nil)
+			},
+			status: http.StatusOK,
+		}, {
+			name:   "restore person not deleted",
+			method: http.MethodPost,
+			path:   "/person/4/restore",
+			preparation: func(svc *mock_service.MockPersonService) {
+				svc.EXPECT().RestorePerson(gomock.Any(), 4).Return(nil, domainErr.ErrPersonNotFound)
+			},
+			status: http.StatusNotFound,
+		}, {
+			name:   "get deleted person without include_deleted",
+			method: http.MethodGet,
+			path:   "/person/3",
+			preparation: func(svc *mock_service.MockPersonService) {
+				svc.EXPECT().GetPerson(gomock.Any(), 3, false).Return(nil, domainErr.ErrPersonNotFound)
+			},
+			status: http.StatusNotFound,
+		}, {
+			name:   "get deleted person",
+			method: http.MethodGet,
+			path:   "/person/3?include_deleted=true",
+			preparation: func(svc *mock_service.MockPersonService) {
+				svc.EXPECT().GetPerson(gomock.Any(), 3, true).Return(&model.Person{Id: 3}, nil)
+			},
+			status: http.StatusOK,
+		},
+	}
+	gin.SetMode(gin.TestMode)
+	for _, testCases := range cases {
+		t.Run(testCases.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			svc := mock_service.NewMockPersonService(ctrl)
+			testCases.preparation(svc)
+
+			router := NewPersonRouter(svc)
+			server := gin.New()
+			server.GET("/person/:id", router.GetPerson)
+			server.DELETE("/person", router.DeletePerson)
+			server.POST("/person/:id/restore", router.RestorePerson)
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(testCases.method, testCases.path, strings.NewReader(testCases.body))
+			req.Header.Set("Content-Type", "application/json")
+			server.ServeHTTP(w, req)
+
+			if w.Code != testCases.status {
+				t.Errorf("got status %d, want %d", w.Code, testCases.status)
+			}
+		})
+	}
+}
+
+func TestPersonRouter_UpdatePerson(t *testing.T) {
+	cases := []struct {
+		name    string
+		ifMatch string
+		body    string
+		version int64
+		err     error
+		status  int
+		etag    string
+	}{
+		{
+			name:    "if match",
+			ifMatch: `"3"`,
+			body:    `{"id":"1","name":"Olga"}`,
+			version: 3,
+			status:  http.StatusOK,
+			etag:    `"4"`,
+		}, {
+			name:    "weak if match",
+			ifMatch: `W/"3"`,
+			body:    `{"id":"1","name":"Olga"}`,
+			version: 3,
+			status:  http.StatusOK,
+			etag:    `"4"`,
+		}, {
+			name:    "if match any",
+			ifMatch: "*",
+			body:    `{"id":"1","name":"Olga","version":5}`,
+			version: repository.AnyVersion,
+			status:  http.StatusOK,
+			etag:    `"8"`,
+		}, {
+			name:    "version field",
+			body:    `{"id":"1","name":"Olga","version":5}`,
+			version: 5,
+			status:  http.StatusOK,
+			etag:    `"6"`,
+		}, {
+			name:    "stale version",
+			ifMatch: `"2"`,
+			body:    `{"id":"1","name":"Olga"}`,
+			version: 2,
+			err:     &repository.ErrVersionConflict{Id: 1, Expected: 2, Current: 3},
+			status:  http.StatusPreconditionFailed,
+		}, {
+			name:   "no version",
+			body:   `{"id":"1","name":"Olga"}`,
+			status: http.StatusPreconditionRequired,
+		}, {
+			name:    "invalid if match",
+			ifMatch: "3",
+			body:    `{"id":"1","name":"Olga"}`,
+			status:  http.StatusBadRequest,
+		},
+	}
+	gin.SetMode(gin.TestMode)
+	for _, testCases := range cases {
+		t.Run(testCases.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			svc := mock_service.NewMockPersonService(ctrl)
+			if testCases.version != 0 {
+				svc.EXPECT().UpdatePerson(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, person *model.Person) error {
+					if person.Version != testCases.version {
+						t.Errorf("got version %d, want %d", person.Version, testCases.version)
+					}
+					if testCases.err != nil {
+						return testCases.err
+					}
+					// Any version updates the person at its current one.
+					if person.Version == repository.AnyVersion {
+						person.Version = 7
+					}
+					person.Version++
+					return nil
+				})
+			}
+
+			server := gin.New()
+			server.PATCH("/person", NewPersonRouter(svc).UpdatePerson)
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPatch, "/person", strings.NewReader(testCases.body))
+			req.Header.Set("Content-Type", "application/json")
+			if testCases.ifMatch != "" {
+				req.Header.Set("If-Match", testCases.ifMatch)
+			}
+			server.ServeHTTP(w, req)
+
+			if w.Code != testCases.status {
+				t.Errorf("got status %d, want %d", w.Code, testCases.status)
+			}
+			if got := w.Header().Get("ETag"); got != testCases.etag {
+				t.Errorf("got ETag %q, want %q", got, testCases.etag)
+			}
+		})
+	}
+}
+
+func TestPersonRouter_GetPersonAsOf(t *testing.T) {
+	asOf := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name   string
+		query  string
+		err    error
+		status int
+	}{
+		{
+			name:   "past state",
+			query:  "?as_of=2024-04-01T12:00:00Z",
+			status: http.StatusOK,
+		}, {
+			name:   "no state by then",
+			query:  "?as_of=2024-04-01T12:00:00Z",
+			err:    domainErr.ErrPersonNotFound,
+			status: http.StatusNotFound,
+		}, {
+			name:   "invalid as of",
+			query:  "?as_of=yesterday",
+			status: http.StatusBadRequest,
+		},
+	}
+	gin.SetMode(gin.TestMode)
+	for _, testCases := range cases {
+		t.Run(testCases.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			svc := mock_service.NewMockPersonService(ctrl)
+			if testCases.status != http.StatusBadRequest {
+				var person *model.Person
+				if testCases.err == nil {
+					person = &model.Person{Id: 3, Version: 2}
+				}
+				svc.EXPECT().GetPersonAsOf(gomock.Any(), 3, asOf, false).Return(person, testCases.err)
+			}
+
+			server := gin.New()
+			server.GET("/person/:id", NewPersonRouter(svc).GetPerson)
+			w := httptest.NewRecorder()
+			server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/person/3"+testCases.query, nil))
+
+			if w.Code != testCases.status {
+				t.Errorf("got status %d, want %d", w.Code, testCases.status)
+			}
+			if got := w.Header().Get("ETag"); got != "" {
+				t.Errorf("got ETag %q for a past state", got)
+			}
+		})
+	}
+}
+
+func reenrichFilter(nationality string, enrichedBefore time.Time) *dto.ReenrichFilter {
+	filter := &dto.ReenrichFilter{EnrichedBefore: &enrichedBefore}
+	filter.Nationalities = []string{nationality}
+	filter.NationalityMode = dto.NationalityModeTop
+	return filter
+}
+
+func TestPersonRouter_ReviewPerson(t *testing.T) {
+	cases := []struct {
+		name   string
+		body   string
+		want   *dto.ReviewDTO
+		err    error
+		status int
+	}{
+		{
+			name:   "confirmation without body",
+			want:   &dto.ReviewDTO{},
+			status: http.StatusOK,
+		}, {
+			name:   "correction",
+			body:   `{"gender":"male"}`,
+			want:   &dto.ReviewDTO{Gender: ptr.To("male")},
+			status: http.StatusOK,
+		}, {
+			name:   "person not under review",
+			want:   &dto.ReviewDTO{},
+			err:    domainErr.ErrNotUnderReview,
+			status: http.StatusConflict,
+		}, {
+			name:   "person changed meanwhile",
+			want:   &dto.ReviewDTO{},
+			err:    &repository.ErrVersionConflict{Id: 4, Expected: 2, Current: 3},
+			status: http.StatusConflict,
+		}, {
+			name:   "malformed body",
+			body:   `{"age":"old"}`,
+			status: http.StatusBadRequest,
+		},
+	}
+	gin.SetMode(gin.TestMode)
+	for _, testCases := range cases {
+		t.Run(testCases.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			svc := mock_service.NewMockPersonService(ctrl)
+			if testCases.want != nil {
+				svc.EXPECT().ReviewPerson(gomock.Any(), 4, testCases.want).Return(&model.Person{Id: 4}, testCases.err)
+			}
+
+			server := gin.New()
+			server.POST("/review/:id", NewPersonRouter(svc).ReviewPerson)
+			w := httptest.NewRecorder()
+			server.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/review/4", strings.NewReader(testCases.body)))
+
+			if w.Code != testCases.status {
+				t.Errorf("got status %d, want %d", w.Code, testCases.status)
+			}
+		})
+	}
+}
+
+func TestPersonRouter_ReenrichPerson(t *testing.T) {
+	cases := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{
+			name:   "re-enriched",
+			status: http.StatusOK,
+		}, {
+			name:   "missing or deleted person",
+			err:    domainErr.ErrPersonNotFound,
+			status: http.StatusNotFound,
+		}, {
+			name:   "person changed meanwhile",
+			err:    &repository.ErrVersionConflict{Id: 4, Expected: 3, Current: 4},
+			status: http.StatusConflict,
+		}, {
+			name:   "providers unavailable",
+			err:    domainErr.ErrProviderUnavailable,
+			status: http.StatusServiceUnavailable,
+		},
+	}
+	gin.SetMode(gin.TestMode)
+	for _, testCases := range cases {
+		t.Run(testCases.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			svc := mock_service.NewMockPersonService(ctrl)
+			svc.EXPECT().ReenrichPerson(gomock.Any(), 4).Return(&model.Person{Id: 4}, testCases.err)
+
+			server := gin.New()
+			server.POST("/person/:id/enrich", NewPersonRouter(svc).ReenrichPerson)
+			w := httptest.NewRecorder()
+			server.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/person/4/enrich", nil))
+
+			if w.Code != testCases.status {
+				t.Errorf("got status %d, want %d", w.Code, testCases.status)
+			}
+		})
+	}
+}
+
+func TestPersonRouter_GetCoalescingStats(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	svc := mock_service.NewMockPersonService(ctrl)
+	svc.EXPECT().CoalescingStats(gomock.Any()).Return(enricher.CoalescingStats{Calls: 3, Coalesced: 2, Upstream: 1})
+
+	server := gin.New()
+	server.GET("/admin/coalescing", NewPersonRouter(svc).GetCoalescingStats)
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/coalescing", nil))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("got status %d, want %d", w.Code, http.StatusOK)
+	}
+	if got, want := w.Body.String(), `{"calls":3,"coalesced":2,"upstream":1}`; got != want {
+		t.Errorf("got body %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,38 @@
+package app
+
+import (
+	"errors"
+	domainErr "github.com/Kosodaka/enricher-service/internal/domain/errors"
+	"github.com/Kosodaka/enricher-service/internal/domain/ports/repository"
+	"github.com/gin-gonic/gin"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+// enrichmentStatus maps an enrichment failure to the HTTP status telling our clients
+// whether to fix their request or to retry it later.
+func enrichmentStatus(err error) int {
+	switch {
+	case errors.Is(err, domainErr.ErrProviderRateLimited):
+		return http.StatusTooManyRequests
+	case errors.Is(err, domainErr.ErrProviderUnavailable):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, domainErr.ErrProviderBadResponse):
+		return http.StatusBadGateway
+	case errors.Is(err, domainErr.ErrNameUnknown):
+		return http.StatusUnprocessableEntity
+	case errors.As(err, new(*repository.ErrVersionConflict)):
+		// The person was changed while the providers were asked, enriching it again is safe.
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// setRetryAfter passes the wait requested by the providers on to the client.
+func setRetryAfter(c *gin.Context, err error) {
+	if wait := domainErr.RetryAfter(err); wait > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	}
+}
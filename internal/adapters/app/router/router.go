@@ -1,6 +1,7 @@
 package router
 
 import (
+	"github.com/Kosodaka/enricher-service/pkg/audit"
 	"github.com/gin-gonic/gin"
 )
 
@@ -13,7 +14,21 @@ type personRouter interface {
 	GetPerson(c *gin.Context)
 	UpdatePerson(c *gin.Context)
 	DeletePerson(c *gin.Context)
+	RestorePerson(c *gin.Context)
 	GetPersons(c *gin.Context)
+	SearchPersons(c *gin.Context)
+	GetProviders(c *gin.Context)
+	GetJob(c *gin.Context)
+	GetProviderQuotas(c *gin.Context)
+	GetCoalescingStats(c *gin.Context)
+	ReenrichPerson(c *gin.Context)
+	StartReenrich(c *gin.Context)
+	GetReenrich(c *gin.Context)
+	CancelReenrich(c *gin.Context)
+	GetEnrichmentLog(c *gin.Context)
+	GetPersonHistory(c *gin.Context)
+	GetReviewQueue(c *gin.Context)
+	ReviewPerson(c *gin.Context)
 }
 
 type Router struct {
@@ -27,11 +42,41 @@ func (r *Router) InitRoutes() {
 	r.Server.POST("/persons", r.PersonRouter.AddPerson)
 	r.Server.GET("/person/:id", r.PersonRouter.GetPerson)
 	r.Server.GET("/persons", r.PersonRouter.GetPersons)
+	r.Server.GET("/persons/search", r.PersonRouter.SearchPersons)
 	r.Server.PATCH("/person", r.PersonRouter.UpdatePerson)
 	r.Server.DELETE("/person", r.PersonRouter.DeletePerson)
+	r.Server.POST("/person/:id/restore", r.PersonRouter.RestorePerson)
+	r.Server.POST("/person/:id/enrich", r.PersonRouter.ReenrichPerson)
+	r.Server.GET("/person/:id/enrichment-log", r.PersonRouter.GetEnrichmentLog)
+	r.Server.GET("/person/:id/history", r.PersonRouter.GetPersonHistory)
+	r.Server.GET("/admin/providers", r.PersonRouter.GetProviders)
+	r.Server.GET("/admin/providers/quota", r.PersonRouter.GetProviderQuotas)
+	r.Server.GET("/admin/coalescing", r.PersonRouter.GetCoalescingStats)
+	r.Server.POST("/admin/reenrich", r.PersonRouter.StartReenrich)
+	r.Server.GET("/admin/reenrich/:id", r.PersonRouter.GetReenrich)
+	r.Server.DELETE("/admin/reenrich/:id", r.PersonRouter.CancelReenrich)
+	r.Server.GET("/jobs/:id", r.PersonRouter.GetJob)
+	r.Server.GET("/review", r.PersonRouter.GetReviewQueue)
+	r.Server.POST("/review/:id", r.PersonRouter.ReviewPerson)
 
 }
 
+// origin puts who made the request and its id into the request context, the change
+// history of persons records them. A request without an X-Request-Id is given one.
+// The service does not authenticate requests, so X-Actor is only what the client claims:
+// it is trustworthy only behind a proxy that authenticates the caller and sets the header.
+func origin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestId := c.GetHeader("X-Request-Id")
+		if requestId == "" {
+			requestId = audit.NewRequestId()
+		}
+		c.Header("X-Request-Id", requestId)
+		c.Request = c.Request.WithContext(audit.WithOrigin(c.Request.Context(), c.GetHeader("X-Actor"), requestId))
+		c.Next()
+	}
+}
+
 func (r *Router) Run() error {
 	return r.Server.Run(":" + r.Port)
 }
@@ -42,7 +87,7 @@ func NewRouter(cfg Config, p personRouter) *Router {
 		Port:         cfg.GetHTTPPort(),
 	}
 	router.Server = gin.Default()
-	router.Server.Use(gin.Recovery())
+	router.Server.Use(gin.Recovery(), origin())
 
 	router.InitRoutes()
 	return router
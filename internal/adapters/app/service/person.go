@@ -4,12 +4,30 @@ import (
 	"context"
 	"github.com/Kosodaka/enricher-service/internal/domain/dto"
 	"github.com/Kosodaka/enricher-service/internal/domain/model"
+	"github.com/Kosodaka/enricher-service/internal/domain/ports/enricher"
+	"time"
 )
 
 type PersonService interface {
 	AddPerson(ctx context.Context, data *dto.AddPersonDTO) (int, error)
-	GetPerson(ctx context.Context, id int) (*model.Person, error)
-	GetPersons(ctx context.Context, data *model.Person) ([]model.Person, error)
+	GetPerson(ctx context.Context, id int, includeDeleted bool) (*model.Person, error)
+	GetPersonAsOf(ctx context.Context, id int, asOf time.Time, includeDeleted bool) (*model.Person, error)
+	GetPersonHistory(ctx context.Context, id int) ([]model.PersonHistory, error)
+	GetPersons(ctx context.Context, data *dto.PersonFilter) (*dto.PersonPage, error)
+	SearchPersons(ctx context.Context, query string, limit int, includeDeleted bool) ([]dto.PersonMatch, error)
 	UpdatePerson(ctx context.Context, data *model.Person) error
 	DeletePerson(ctx context.Context, id int) error
+	RestorePerson(ctx context.Context, id int) (*model.Person, error)
+	ProviderStatuses(ctx context.Context) []enricher.ProviderStatus
+	ProviderQuotas(ctx context.Context) []enricher.ProviderQuota
+	CoalescingStats(ctx context.Context) enricher.CoalescingStats
+	AddPersonAsync(ctx context.Context, data *dto.AddPersonDTO) (int, int, error)
+	GetJob(ctx context.Context, id int) (*model.Job, error)
+	ReenrichPerson(ctx context.Context, id int) (*model.Person, error)
+	StartReenrich(ctx context.Context, filter *dto.ReenrichFilter) (*model.ReenrichRun, error)
+	GetReenrich(ctx context.Context, id int) (*model.ReenrichRun, error)
+	CancelReenrich(ctx context.Context, id int) (*model.ReenrichRun, error)
+	GetEnrichmentLog(ctx context.Context, id int) ([]model.EnrichmentLog, error)
+	GetReviewQueue(ctx context.Context) ([]model.Person, error)
+	ReviewPerson(ctx context.Context, id int, data *dto.ReviewDTO) (*model.Person, error)
 }
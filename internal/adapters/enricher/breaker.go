@@ -0,0 +1,99 @@
+package enricher
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	StateClosed   = "closed"
+	StateOpen     = "open"
+	StateHalfOpen = "half-open"
+)
+
+// breaker is a per-provider circuit breaker. It opens after threshold consecutive
+// failures, rejects calls while open and lets a single probe through once
+// openTimeout has elapsed. The probe's outcome closes or re-opens it.
+type breaker struct {
+	mu          sync.Mutex
+	state       string
+	failures    int
+	threshold   int
+	openTimeout time.Duration
+	openedAt    time.Time
+	probing     bool
+}
+
+func newBreaker(threshold int, openTimeout time.Duration) *breaker {
+	if threshold <= 0 {
+		threshold = 1
+	}
+	return &breaker{
+		state:       StateClosed,
+		threshold:   threshold,
+		openTimeout: openTimeout,
+	}
+}
+
+// allow reports whether a call may be made now.
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if time.Since(b.openedAt) < b.openTimeout {
+			return false
+		}
+		b.state = StateHalfOpen
+		b.probing = true
+		return true
+	case StateHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+func (b *breaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = StateClosed
+	b.failures = 0
+	b.probing = false
+}
+
+func (b *breaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if b.state == StateHalfOpen || b.failures >= b.threshold {
+		b.state = StateOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// abort releases a probe whose call ended without a verdict, e.g. on context cancellation.
+func (b *breaker) abort() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+func (b *breaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateOpen && time.Since(b.openedAt) >= b.openTimeout {
+		return StateHalfOpen
+	}
+	return b.state
+}
@@ -0,0 +1,190 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"github.com/Kosodaka/enricher-service/internal/domain/ports/enricher"
+	"github.com/Kosodaka/enricher-service/internal/domain/ports/repository"
+	"github.com/Kosodaka/enricher-service/pkg/lru"
+	"github.com/Kosodaka/enricher-service/pkg/names"
+	"strings"
+	"time"
+)
+
+type Configer interface {
+	GetEnrichCacheSize() int
+	GetEnrichCacheTTL() time.Duration
+}
+
+// Enricher serves enrichment results from an in-process LRU backed by a
+// persistent store and only calls the wrapped enricher on a miss in both.
+type Enricher struct {
+	next  enricher.Enricher
+	store repository.EnrichmentCacheRepository
+	lru   *lru.Cache[string, enricher.EnrichData]
+	ttl   time.Duration
+}
+
+func NewEnricher(next enricher.Enricher, store repository.EnrichmentCacheRepository, cfg Configer) *Enricher {
+	return &Enricher{
+		next:  next,
+		store: store,
+		lru:   lru.New[string, enricher.EnrichData](cfg.GetEnrichCacheSize(), cfg.GetEnrichCacheTTL()),
+		ttl:   cfg.GetEnrichCacheTTL(),
+	}
+}
+
+// cacheKey is the normalized name, suffixed by the country for localized predictions
+// and by the skipped attributes for partial ones.
+func cacheKey(name string, countryId string, skip ...string) string {
+	key := names.Normalize(name)
+	if countryId != "" {
+		key += "@" + countryId
+	}
+	if len(skip) > 0 {
+		key += "-" + strings.Join(skip, "-")
+	}
+	return key
+}
+
+func (e *Enricher) Enrich(ctx context.Context, name string, countryId string, skip ...string) (*enricher.EnrichData, error) {
+	key := cacheKey(name, countryId, skip...)
+	if data := e.lookup(ctx, key); data != nil {
+		return data, nil
+	}
+
+	data, err := e.next.Enrich(ctx, name, countryId, skip...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Provider failures are not cached so the attribute is fetched again next time.
+	if !data.Failed() {
+		e.save(ctx, key, data)
+	}
+	return data, nil
+}
+
+// EnrichBatch answers cached names locally and sends only the misses to the wrapped enricher,
+// one name per normalized key.
+func (e *Enricher) EnrichBatch(ctx context.Context, batch []string, countryId string) (map[string]enricher.BatchResult, error) {
+	results := make(map[string]enricher.BatchResult, len(batch))
+	missed := map[string][]string{}
+	misses := []string{}
+	for _, name := range batch {
+		key := cacheKey(name, countryId)
+		if _, ok := missed[key]; ok {
+			missed[key] = append(missed[key], name)
+			continue
+		}
+		if data := e.lookup(ctx, key); data != nil {
+			results[name] = enricher.BatchResult{Data: data, Err: data.Err}
+			continue
+		}
+		missed[key] = []string{name}
+		misses = append(misses, name)
+	}
+	if len(misses) == 0 {
+		return results, nil
+	}
+
+	fetched, err := e.next.EnrichBatch(ctx, misses, countryId)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range misses {
+		key := cacheKey(name, countryId)
+		res, ok := fetched[name]
+		if !ok {
+			res = enricher.BatchResult{Err: fmt.Errorf("no result for %s", name)}
+		}
+		// Unknown names are cached as Enrich caches them, only provider failures are asked again.
+		if res.Data != nil && !res.Data.Failed() {
+			e.save(ctx, key, res.Data)
+		}
+		for _, alias := range missed[key] {
+			results[alias] = res
+		}
+	}
+	return results, nil
+}
+
+// Refresher asks the wrapped enricher on every call and caches its fresh results, so that
+// re-enrichment replaces stale predictions instead of reading them back.
+type Refresher struct {
+	cache *Enricher
+}
+
+// Refresher returns the enricher bypassing the lookups of e while still writing to its cache.
+func (e *Enricher) Refresher() *Refresher {
+	return &Refresher{cache: e}
+}
+
+func (r *Refresher) Enrich(ctx context.Context, name string, countryId string, skip ...string) (*enricher.EnrichData, error) {
+	data, err := r.cache.next.Enrich(ctx, name, countryId, skip...)
+	if err != nil {
+		return nil, err
+	}
+	if !data.Failed() {
+		r.cache.save(ctx, cacheKey(name, countryId, skip...), data)
+	}
+	return data, nil
+}
+
+func (r *Refresher) EnrichBatch(ctx context.Context, batch []string, countryId string) (map[string]enricher.BatchResult, error) {
+	results, err := r.cache.next.EnrichBatch(ctx, batch, countryId)
+	if err != nil {
+		return nil, err
+	}
+	for name, res := range results {
+		if res.Data != nil && !res.Data.Failed() {
+			r.cache.save(ctx, cacheKey(name, countryId), res.Data)
+		}
+	}
+	return results, nil
+}
+
+// lookup returns the cached data for key or nil on a miss. A broken store must not
+// break enrichment, so its errors are treated as misses.
+func (e *Enricher) lookup(ctx context.Context, key string) *enricher.EnrichData {
+	if data, ok := e.lru.Get(key); ok {
+		return hit(data)
+	}
+	if e.store == nil {
+		return nil
+	}
+
+	var notBefore time.Time
+	if e.ttl > 0 {
+		notBefore = time.Now().Add(-e.ttl)
+	}
+	data, err := e.store.GetEnrichment(ctx, key, notBefore)
+	if err != nil || data == nil {
+		return nil
+	}
+	e.lru.Add(key, *data)
+	return hit(*data)
+}
+
+// hit returns the cached data with its provider answers marked as cached, so the
+// enrichment log of every person served from the cache points at the original fetch.
+func hit(data enricher.EnrichData) *enricher.EnrichData {
+	if len(data.Responses) == 0 {
+		return &data
+	}
+	responses := make([]enricher.ProviderResponse, len(data.Responses))
+	for i, resp := range data.Responses {
+		resp.Cached = true
+		responses[i] = resp
+	}
+	data.Responses = responses
+	return &data
+}
+
+// save caches data together with the provider answers it was predicted from.
+func (e *Enricher) save(ctx context.Context, key string, data *enricher.EnrichData) {
+	e.lru.Add(key, *data)
+	if e.store != nil {
+		_ = e.store.SaveEnrichment(ctx, key, data)
+	}
+}
@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"context"
+	"github.com/Kosodaka/enricher-service/internal/domain/ports/enricher"
+	mock_enricher "github.com/Kosodaka/enricher-service/pkg/mocks/api/enricher"
+	mock_repository "github.com/Kosodaka/enricher-service/pkg/mocks/api/repository"
+	"github.com/Kosodaka/enricher-service/pkg/ptr"
+	"github.com/golang/mock/gomock"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testConfig struct{}
+
+func (testConfig) GetEnrichCacheSize() int          { return 10 }
+func (testConfig) GetEnrichCacheTTL() time.Duration { return time.Hour }
+
+func TestEnricher_Enrich(t *testing.T) {
+	enrichData := &enricher.EnrichData{
+		Age:         ptr.To(60),
+		Gender:      ptr.To("male"),
+		Nationality: ptr.To("RU"),
+	}
+
+	cases := []struct {
+		name        string
+		input       []string
+		countryId   string
+		preparation func(next *mock_enricher.MockEnricher, store *mock_repository.MockEnrichmentCacheRepository)
+	}{
+		{
+			name:  "repeated name hits lru",
+			input: []string{"Oleg", "oleg", " OLEG "},
+			preparation: func(next *mock_enricher.MockEnricher, store *mock_repository.MockEnrichmentCacheRepository) {
+				store.EXPECT().GetEnrichment(gomock.Any(), "oleg", gomock.Any()).Return(nil, nil).Times(1)
+				next.EXPECT().Enrich(gomock.Any(), "Oleg", "").Return(enrichData, nil).Times(1)
+				store.EXPECT().SaveEnrichment(gomock.Any(), "oleg", enrichData).Return(nil).Times(1)
+			},
+		},
+		{
+			name:  "stored name skips providers",
+			input: []string{"Oleg", "Oleg"},
+			preparation: func(next *mock_enricher.MockEnricher, store *mock_repository.MockEnrichmentCacheRepository) {
+				store.EXPECT().GetEnrichment(gomock.Any(), "oleg", gomock.Any()).Return(enrichData, nil).Times(1)
+			},
+		},
+		{
+			name:      "country is part of the key",
+			input:     []string{"Oleg", "oleg"},
+			countryId: "KZ",
+			preparation: func(next *mock_enricher.MockEnricher, store *mock_repository.MockEnrichmentCacheRepository) {
+				store.EXPECT().GetEnrichment(gomock.Any(), "oleg@KZ", gomock.Any()).Return(nil, nil).Times(1)
+				next.EXPECT().Enrich(gomock.Any(), "Oleg", "KZ").Return(enrichData, nil).Times(1)
+				store.EXPECT().SaveEnrichment(gomock.Any(), "oleg@KZ", enrichData).Return(nil).Times(1)
+			},
+		},
+	}
+
+	for _, testCases := range cases {
+		t.Run(testCases.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			next := mock_enricher.NewMockEnricher(ctrl)
+			store := mock_repository.NewMockEnrichmentCacheRepository(ctrl)
+			testCases.preparation(next, store)
+
+			e := NewEnricher(next, store, testConfig{})
+			for _, name := range testCases.input {
+				result, err := e.Enrich(context.Background(), name, testCases.countryId)
+				if err != nil {
+					t.Fatalf("got error %v", err)
+				}
+				if !reflect.DeepEqual(result, enrichData) {
+					t.Errorf("got %v, want %v", result, enrichData)
+				}
+			}
+		})
+	}
+}
+
+func TestEnricher_EnrichMarksCachedResponses(t *testing.T) {
+	fetched := &enricher.EnrichData{
+		Age:       ptr.To(60),
+		Responses: []enricher.ProviderResponse{{Provider: "agify", Status: 200, Body: `{"age":60}`}},
+	}
+	cached := &enricher.EnrichData{
+		Age:       ptr.To(60),
+		Responses: []enricher.ProviderResponse{{Provider: "agify", Status: 200, Body: `{"age":60}`, Cached: true}},
+	}
+
+	ctrl := gomock.NewController(t)
+	next := mock_enricher.NewMockEnricher(ctrl)
+	store := mock_repository.NewMockEnrichmentCacheRepository(ctrl)
+	store.EXPECT().GetEnrichment(gomock.Any(), "oleg", gomock.Any()).Return(nil, nil).Times(1)
+	next.EXPECT().Enrich(gomock.Any(), "Oleg", "").Return(fetched, nil).Times(1)
+	store.EXPECT().SaveEnrichment(gomock.Any(), "oleg", fetched).Return(nil).Times(1)
+	store.EXPECT().GetEnrichment(gomock.Any(), "ivan", gomock.Any()).Return(fetched, nil).Times(1)
+
+	e := NewEnricher(next, store, testConfig{})
+	cases := []struct {
+		name string
+		want *enricher.EnrichData
+	}{
+		{name: "Oleg", want: fetched},
+		// A hit serves the original provider answers marked as cached, from the LRU and from the store.
+		{name: "Oleg", want: cached},
+		{name: "Ivan", want: cached},
+		{name: "Ivan", want: cached},
+	}
+	for i, testCases := range cases {
+		result, err := e.Enrich(context.Background(), testCases.name, "")
+		if err != nil {
+			t.Fatalf("got error %v", err)
+		}
+		if !reflect.DeepEqual(result, testCases.want) {
+			t.Errorf("call %d: got %+v, want %+v", i+1, result, testCases.want)
+		}
+	}
+	if fetched.Responses[0].Cached {
+		t.Errorf("the fetched responses were marked as cached")
+	}
+}
+
+func TestRefresher_Enrich(t *testing.T) {
+	stale := &enricher.EnrichData{Age: ptr.To(60), Gender: ptr.To("male"), Nationality: ptr.To("RU")}
+	fresh := &enricher.EnrichData{Age: ptr.To(61), Gender: ptr.To("male"), Nationality: ptr.To("RU")}
+
+	ctrl := gomock.NewController(t)
+	next := mock_enricher.NewMockEnricher(ctrl)
+	store := mock_repository.NewMockEnrichmentCacheRepository(ctrl)
+	store.EXPECT().GetEnrichment(gomock.Any(), "oleg", gomock.Any()).Return(stale, nil).Times(1)
+	next.EXPECT().Enrich(gomock.Any(), "Oleg", "").Return(fresh, nil).Times(1)
+	store.EXPECT().SaveEnrichment(gomock.Any(), "oleg", fresh).Return(nil).Times(1)
+
+	e := NewEnricher(next, store, testConfig{})
+	if result, _ := e.Enrich(context.Background(), "Oleg", ""); !reflect.DeepEqual(result, stale) {
+		t.Fatalf("got %v, want the cached %v", result, stale)
+	}
+	// A cached name still reaches the provider, and the fresh answer replaces the cached one.
+	if result, err := e.Refresher().Enrich(context.Background(), "Oleg", ""); err != nil || !reflect.DeepEqual(result, fresh) {
+		t.Fatalf("got %v, %v, want %v", result, err, fresh)
+	}
+	if result, _ := e.Enrich(context.Background(), "Oleg", ""); !reflect.DeepEqual(result, fresh) {
+		t.Errorf("got %v, want the refreshed %v", result, fresh)
+	}
+}
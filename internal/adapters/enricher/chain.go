@@ -0,0 +1,158 @@
+package enricher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	domainErr "github.com/Kosodaka/enricher-service/internal/domain/errors"
+	"github.com/Kosodaka/enricher-service/internal/domain/ports/enricher"
+	"strings"
+)
+
+// AgeChain asks its providers in order and returns the first answer.
+type AgeChain []enricher.AgeProvider
+
+func (c AgeChain) Name() string {
+	names := make([]string, 0, len(c))
+	for _, p := range c {
+		names = append(names, p.Name())
+	}
+	return strings.Join(names, ",")
+}
+
+func (c AgeChain) Age(ctx context.Context, name string, countryId string) (*enricher.Age, error) {
+	return first(ctx, len(c), func(i int) (string, *enricher.Age, error) {
+		res, err := c[i].Age(ctx, name, countryId)
+		return c[i].Name(), res, err
+	})
+}
+
+func (c AgeChain) Ages(ctx context.Context, names []string, countryId string) (map[string]*enricher.Age, error) {
+	return firstMany(ctx, len(c), names, func(i int, names []string) (string, map[string]*enricher.Age, error) {
+		res, err := c[i].Ages(ctx, names, countryId)
+		return c[i].Name(), res, err
+	})
+}
+
+// GenderChain asks its providers in order and returns the first answer.
+type GenderChain []enricher.GenderProvider
+
+func (c GenderChain) Name() string {
+	names := make([]string, 0, len(c))
+	for _, p := range c {
+		names = append(names, p.Name())
+	}
+	return strings.Join(names, ",")
+}
+
+func (c GenderChain) Gender(ctx context.Context, name string, countryId string) (*enricher.Gender, error) {
+	return first(ctx, len(c), func(i int) (string, *enricher.Gender, error) {
+		res, err := c[i].Gender(ctx, name, countryId)
+		return c[i].Name(), res, err
+	})
+}
+
+func (c GenderChain) Genders(ctx context.Context, names []string, countryId string) (map[string]*enricher.Gender, error) {
+	return firstMany(ctx, len(c), names, func(i int, names []string) (string, map[string]*enricher.Gender, error) {
+		res, err := c[i].Genders(ctx, names, countryId)
+		return c[i].Name(), res, err
+	})
+}
+
+// NationalityChain asks its providers in order and returns the first answer.
+type NationalityChain []enricher.NationalityProvider
+
+func (c NationalityChain) Name() string {
+	names := make([]string, 0, len(c))
+	for _, p := range c {
+		names = append(names, p.Name())
+	}
+	return strings.Join(names, ",")
+}
+
+func (c NationalityChain) Nationality(ctx context.Context, name string) (*enricher.Nationality, error) {
+	return first(ctx, len(c), func(i int) (string, *enricher.Nationality, error) {
+		res, err := c[i].Nationality(ctx, name)
+		return c[i].Name(), res, err
+	})
+}
+
+func (c NationalityChain) Nationalities(ctx context.Context, names []string) (map[string]*enricher.Nationality, error) {
+	return firstMany(ctx, len(c), names, func(i int, names []string) (string, map[string]*enricher.Nationality, error) {
+		res, err := c[i].Nationalities(ctx, names)
+		return c[i].Name(), res, err
+	})
+}
+
+// first calls the n providers in order until one of them answers. Otherwise it returns
+// the last provider failure, or an unknown name error when every provider was asked fine.
+// Provider failures are returned as *domainErr.ProviderError naming the provider.
+// The remaining providers are not asked once ctx is done.
+func first[T any](ctx context.Context, n int, call func(i int) (string, *T, error)) (*T, error) {
+	var err error
+	for i := 0; i < n; i++ {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, pickError(err, ctxErr)
+		}
+		provider, res, callErr := call(i)
+		if callErr == nil && res != nil {
+			return res, nil
+		}
+		err = pickError(err, providerError(provider, callErr))
+	}
+	if err == nil {
+		err = fmt.Errorf("no provider answered: %w", domainErr.ErrNameUnknown)
+	}
+	return nil, err
+}
+
+// providerError names the provider in err unless the provider already did.
+func providerError(provider string, err error) error {
+	var providerErr *domainErr.ProviderError
+	if err == nil || errors.As(err, &providerErr) {
+		return err
+	}
+	return &domainErr.ProviderError{Provider: provider, Err: err}
+}
+
+// pickError prefers provider failures over unknown name errors, which are expected answers.
+func pickError(current error, next error) error {
+	if next == nil {
+		return current
+	}
+	if current == nil || !errors.Is(next, domainErr.ErrNameUnknown) {
+		return next
+	}
+	return current
+}
+
+// firstMany passes the names left unanswered by each provider on to the next one.
+// The last provider error is returned when some names stay unanswered.
+func firstMany[T any](ctx context.Context, n int, names []string, call func(i int, names []string) (string, map[string]*T, error)) (map[string]*T, error) {
+	results := make(map[string]*T, len(names))
+	var err error
+	for i := 0; i < n && len(names) > 0; i++ {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			err = pickError(err, ctxErr)
+			break
+		}
+		provider, res, callErr := call(i, names)
+		err = pickError(err, providerError(provider, callErr))
+		remaining := make([]string, 0, len(names))
+		for _, name := range names {
+			if value, ok := res[name]; ok && value != nil {
+				results[name] = value
+				continue
+			}
+			remaining = append(remaining, name)
+		}
+		names = remaining
+	}
+	if len(names) == 0 {
+		return results, nil
+	}
+	if err == nil {
+		err = fmt.Errorf("no provider answered %d names: %w", len(names), domainErr.ErrNameUnknown)
+	}
+	return results, err
+}
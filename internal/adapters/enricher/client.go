@@ -0,0 +1,233 @@
+package enricher
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	domainErr "github.com/Kosodaka/enricher-service/internal/domain/errors"
+	"github.com/Kosodaka/enricher-service/internal/domain/ports/enricher"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// maxBodySize caps the provider answers read into memory.
+const maxBodySize = 1 << 20
+
+// statusError is returned for a non-200 provider response.
+type statusError struct {
+	code       int
+	retryAfter time.Duration
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("unexpected status %d", e.code)
+}
+
+// apiClient calls one HTTP provider through its circuit breaker, retrying transient
+// failures with jittered exponential backoff.
+type apiClient struct {
+	name    string
+	url     string
+	client  *http.Client
+	retry   RetryPolicy
+	breaker *breaker
+	usage   *quota
+}
+
+func newApiClient(name string, apiUrl string, cfg Configer) *apiClient {
+	return &apiClient{
+		name:   name,
+		url:    apiUrl,
+		client: &http.Client{},
+		retry: RetryPolicy{
+			Attempts:  cfg.GetRetryAttempts(),
+			BaseDelay: cfg.GetRetryBaseDelay(),
+			MaxDelay:  cfg.GetRetryMaxDelay(),
+		},
+		breaker: newBreaker(cfg.GetBreakerThreshold(), cfg.GetBreakerOpenTimeout()),
+		usage:   newQuota(name, cfg.GetDailyBudget()),
+	}
+}
+
+func (c *apiClient) Name() string {
+	return c.name
+}
+
+// State reports the state of the provider's circuit breaker.
+func (c *apiClient) State() string {
+	return c.breaker.State()
+}
+
+// get requests the provider with query and decodes the JSON body into out.
+func (c *apiClient) get(ctx context.Context, query url.Values, out interface{}) error {
+	b := c.breaker
+	if !b.allow() {
+		return &domainErr.ProviderError{
+			Provider: c.name,
+			Err:      fmt.Errorf("%w: circuit is open", domainErr.ErrProviderUnavailable),
+		}
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err := c.usage.reserve(); err != nil {
+			b.abort()
+			return err
+		}
+		err = c.do(ctx, c.url+"?"+query.Encode(), out)
+		if err == nil || !transient(err) || attempt >= c.retry.Attempts {
+			break
+		}
+
+		delay := c.retry.backoff(attempt)
+		var statusErr *statusError
+		if errors.As(err, &statusErr) && statusErr.retryAfter > 0 {
+			if statusErr.retryAfter > c.retry.MaxDelay {
+				break
+			}
+			delay = statusErr.retryAfter
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			b.abort()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	switch {
+	case err == nil:
+		b.success()
+		return nil
+	case ctx.Err() != nil:
+		b.abort()
+		return err
+	case transient(err):
+		b.failure()
+		return c.providerError(err)
+	default:
+		// The provider answered, it just did not like the request.
+		b.success()
+		return c.providerError(err)
+	}
+}
+
+// providerError classifies a failed request by the provider's answer.
+func (c *apiClient) providerError(err error) *domainErr.ProviderError {
+	providerErr := &domainErr.ProviderError{Provider: c.name}
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		providerErr.Status = statusErr.code
+		providerErr.RetryAfter = statusErr.retryAfter
+	}
+	switch {
+	case providerErr.Status == http.StatusTooManyRequests:
+		providerErr.Err = domainErr.ErrProviderRateLimited
+	case providerErr.Status != 0 && transient(err):
+		providerErr.Err = domainErr.ErrProviderUnavailable
+	case providerErr.Status != 0:
+		providerErr.Err = domainErr.ErrProviderBadResponse
+	case transient(err):
+		providerErr.Err = fmt.Errorf("%w: %s", domainErr.ErrProviderUnavailable, err)
+	default:
+		providerErr.Err = fmt.Errorf("%w: %s", domainErr.ErrProviderBadResponse, err)
+	}
+	return providerErr
+}
+
+func (c *apiClient) do(ctx context.Context, apiUrl string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", apiUrl, nil)
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	c.usage.observe(resp.Header)
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return err
+	}
+	record(ctx, enricher.ProviderResponse{
+		Provider:   c.name,
+		URL:        apiUrl,
+		Status:     resp.StatusCode,
+		Headers:    headerSubset(resp.Header),
+		Body:       string(body),
+		Latency:    time.Since(start),
+		ReceivedAt: start,
+	})
+
+	if resp.StatusCode != http.StatusOK {
+		retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"))
+		if retryAfter == 0 && resp.StatusCode == http.StatusTooManyRequests {
+			// The providers tell the seconds left until the daily limit resets instead.
+			retryAfter = parseRetryAfter(resp.Header.Get("X-Rate-Limit-Reset"))
+		}
+		return &statusError{code: resp.StatusCode, retryAfter: retryAfter}
+	}
+	if err := json.NewDecoder(bytes.NewReader(body)).Decode(out); err != nil {
+		return fmt.Errorf("error to decode: %w", err)
+	}
+	return nil
+}
+
+// transient reports whether err is worth retrying: network failures, 429 and 5xx.
+func transient(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		return statusErr.code == http.StatusTooManyRequests || statusErr.code >= 500
+	}
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	return !errors.As(err, &syntaxErr) && !errors.As(err, &typeErr)
+}
+
+// parseRetryAfter accepts both forms of the Retry-After header: delay seconds and an HTTP date.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if d := time.Until(date); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+type RetryPolicy struct {
+	Attempts  int
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+// backoff returns a full-jitter delay before retry number attempt.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	ceiling := p.BaseDelay << (attempt - 1)
+	if ceiling <= 0 || ceiling > p.MaxDelay {
+		ceiling = p.MaxDelay
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(ceiling)))
+}
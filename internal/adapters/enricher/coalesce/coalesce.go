@@ -0,0 +1,120 @@
+package coalesce
+
+import (
+	"context"
+	"github.com/Kosodaka/enricher-service/internal/domain/ports/enricher"
+	"github.com/Kosodaka/enricher-service/pkg/names"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Stats counts how enrichment calls were served.
+type Stats = enricher.CoalescingStats
+
+// call is an enrichment in flight shared by every caller asking for the same name.
+type call struct {
+	done    chan struct{}
+	data    *enricher.EnrichData
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// Enricher lets concurrent callers for the same normalized name share one call to
+// the wrapped enricher. Every caller still returns as soon as its own context is done,
+// and the shared call is cancelled once no caller waits for it anymore.
+type Enricher struct {
+	next  enricher.Enricher
+	mu    sync.Mutex
+	calls map[string]*call
+
+	total     atomic.Int64
+	coalesced atomic.Int64
+	upstream  atomic.Int64
+}
+
+func NewEnricher(next enricher.Enricher) *Enricher {
+	return &Enricher{
+		next:  next,
+		calls: map[string]*call{},
+	}
+}
+
+// Stats returns a snapshot of the counters.
+func (e *Enricher) Stats() Stats {
+	return Stats{
+		Calls:     e.total.Load(),
+		Coalesced: e.coalesced.Load(),
+		Upstream:  e.upstream.Load(),
+	}
+}
+
+// CoalescingStats reports the Stats to the admin API.
+func (e *Enricher) CoalescingStats() enricher.CoalescingStats {
+	return e.Stats()
+}
+
+func (e *Enricher) Enrich(ctx context.Context, name string, countryId string, skip ...string) (*enricher.EnrichData, error) {
+	e.total.Add(1)
+	key := names.Normalize(name) + "@" + countryId + "-" + strings.Join(skip, "-")
+
+	e.mu.Lock()
+	c, ok := e.calls[key]
+	if ok {
+		c.waiters++
+		e.coalesced.Add(1)
+	} else {
+		// The shared call must outlive the caller that started it, so it only keeps its values.
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		c = &call{done: make(chan struct{}), waiters: 1, cancel: cancel}
+		e.calls[key] = c
+		e.upstream.Add(1)
+		go e.run(callCtx, key, name, countryId, skip, c)
+	}
+	e.mu.Unlock()
+
+	select {
+	case <-c.done:
+		if c.err != nil {
+			return nil, c.err
+		}
+		data := *c.data
+		return &data, nil
+	case <-ctx.Done():
+		e.leave(key, c)
+		return nil, ctx.Err()
+	}
+}
+
+func (e *Enricher) run(ctx context.Context, key string, name string, countryId string, skip []string, c *call) {
+	defer c.cancel()
+	c.data, c.err = e.next.Enrich(ctx, name, countryId, skip...)
+
+	e.mu.Lock()
+	if e.calls[key] == c {
+		delete(e.calls, key)
+	}
+	e.mu.Unlock()
+	close(c.done)
+}
+
+// leave drops a caller that stopped waiting and cancels the call when it was the last one.
+func (e *Enricher) leave(key string, c *call) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	c.waiters--
+	if c.waiters > 0 {
+		return
+	}
+	c.cancel()
+	if e.calls[key] == c {
+		delete(e.calls, key)
+	}
+}
+
+// EnrichBatch is passed through, batches are already deduplicated by name.
+func (e *Enricher) EnrichBatch(ctx context.Context, batch []string, countryId string) (map[string]enricher.BatchResult, error) {
+	e.upstream.Add(1)
+	return e.next.EnrichBatch(ctx, batch, countryId)
+}
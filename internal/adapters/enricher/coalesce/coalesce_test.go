@@ -0,0 +1,118 @@
+package coalesce
+
+import (
+	"context"
+	"errors"
+	"github.com/Kosodaka/enricher-service/internal/domain/ports/enricher"
+	mock_enricher "github.com/Kosodaka/enricher-service/pkg/mocks/api/enricher"
+	"github.com/Kosodaka/enricher-service/pkg/ptr"
+	"github.com/golang/mock/gomock"
+	"sync"
+	"testing"
+	"time"
+)
+
+// waitFor polls cond until it holds or a second passes.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition was not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestEnricher_EnrichCoalesces(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	next := mock_enricher.NewMockEnricher(ctrl)
+	release := make(chan struct{})
+	next.EXPECT().Enrich(gomock.Any(), gomock.Any(), "").DoAndReturn(func(ctx context.Context, name string, countryId string, skip ...string) (*enricher.EnrichData, error) {
+		<-release
+		return &enricher.EnrichData{Age: ptr.To(40)}, nil
+	}).Times(1)
+
+	e := NewEnricher(next)
+	const callers = 10
+	ctx := context.Background()
+	cancelled, cancel := context.WithCancel(ctx)
+
+	w := &sync.WaitGroup{}
+	errs := make(chan error, callers+1)
+	for i := 0; i < callers; i++ {
+		w.Add(1)
+		go func(i int) {
+			defer w.Done()
+			name := "Ivan"
+			if i%2 == 0 {
+				name = " ivan"
+			}
+			data, err := e.Enrich(ctx, name, "")
+			if err == nil && (data == nil || *data.Age != 40) {
+				err = errors.New("unexpected data")
+			}
+			errs <- err
+		}(i)
+	}
+	w.Add(1)
+	go func() {
+		defer w.Done()
+		_, err := e.Enrich(cancelled, "IVAN", "")
+		if !errors.Is(err, context.Canceled) {
+			errs <- errors.New("cancelled caller did not return its context error")
+		}
+	}()
+
+	waitFor(t, func() bool { return e.Stats().Calls == callers+1 })
+	cancel()
+	waitFor(t, func() bool {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		return e.calls["ivan@-"] != nil && e.calls["ivan@-"].waiters == callers
+	})
+	close(release)
+	w.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+
+	if got, want := e.Stats(), (Stats{Calls: callers + 1, Coalesced: callers, Upstream: 1}); got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestEnricher_EnrichCancelsAbandonedCall(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	next := mock_enricher.NewMockEnricher(ctrl)
+	upstreamDone := make(chan error, 1)
+	next.EXPECT().Enrich(gomock.Any(), "Ivan", "").DoAndReturn(func(ctx context.Context, name string, countryId string, skip ...string) (*enricher.EnrichData, error) {
+		<-ctx.Done()
+		upstreamDone <- ctx.Err()
+		return nil, ctx.Err()
+	}).Times(1)
+	next.EXPECT().Enrich(gomock.Any(), "Ivan", "").Return(&enricher.EnrichData{}, nil).Times(1)
+
+	e := NewEnricher(next)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := e.Enrich(ctx, "Ivan", ""); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want %v", err, context.DeadlineExceeded)
+	}
+	select {
+	case err := <-upstreamDone:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v, want upstream call cancelled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("upstream call was not cancelled")
+	}
+
+	// A new caller starts a fresh call instead of joining the abandoned one.
+	if _, err := e.Enrich(context.Background(), "Ivan", ""); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
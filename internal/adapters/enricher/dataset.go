@@ -0,0 +1,180 @@
+package enricher
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	domainErr "github.com/Kosodaka/enricher-service/internal/domain/errors"
+	"github.com/Kosodaka/enricher-service/internal/domain/ports/enricher"
+	"github.com/Kosodaka/enricher-service/pkg/names"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrNoDataset means the dataset provider is used without a file to load, the repository
+// only holds test data.
+var ErrNoDataset = errors.New(ProviderDataset + ": DATASET_PATH is not set")
+
+// datasetRecord is the statistics known about one name.
+type datasetRecord struct {
+	age             int
+	maleProbability float64
+	count           int
+	countries       []enricher.Country
+}
+
+// Dataset answers every attribute from name statistics held in memory, so persons
+// can be enriched without reaching any upstream API.
+type Dataset struct {
+	records map[string]datasetRecord
+}
+
+// NewDataset loads the dataset from the CSV file at path.
+func NewDataset(path string) (*Dataset, error) {
+	if path == "" {
+		return nil, ErrNoDataset
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to open dataset: %w", ProviderDataset, err)
+	}
+	defer f.Close()
+	return LoadDataset(f)
+}
+
+// LoadDataset reads CSV rows of name,age,male_probability,count,countries where countries
+// is a list of CODE:probability pairs separated by "|". The header row is required.
+func LoadDataset(r io.Reader) (*Dataset, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 5
+	reader.Comment = '#'
+	if _, err := reader.Read(); err != nil {
+		return nil, fmt.Errorf("%s: failed to read header: %w", ProviderDataset, err)
+	}
+
+	d := &Dataset{records: map[string]datasetRecord{}}
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", ProviderDataset, err)
+		}
+		record, err := parseDatasetRow(row)
+		if err != nil {
+			line, _ := reader.FieldPos(0)
+			return nil, fmt.Errorf("%s: line %d: %w", ProviderDataset, line, err)
+		}
+		d.records[names.Normalize(row[0])] = record
+	}
+	return d, nil
+}
+
+func parseDatasetRow(row []string) (datasetRecord, error) {
+	var (
+		record datasetRecord
+		err    error
+	)
+	if record.age, err = strconv.Atoi(row[1]); err != nil {
+		return record, fmt.Errorf("invalid age: %w", err)
+	}
+	if record.maleProbability, err = strconv.ParseFloat(row[2], 64); err != nil {
+		return record, fmt.Errorf("invalid male probability: %w", err)
+	}
+	if record.maleProbability < 0 || record.maleProbability > 1 {
+		return record, fmt.Errorf("male probability %v is out of [0, 1]", record.maleProbability)
+	}
+	if record.count, err = strconv.Atoi(row[3]); err != nil {
+		return record, fmt.Errorf("invalid count: %w", err)
+	}
+	for _, pair := range strings.Split(row[4], "|") {
+		if pair == "" {
+			continue
+		}
+		code, probability, ok := strings.Cut(pair, ":")
+		if !ok {
+			return record, fmt.Errorf("invalid country %q", pair)
+		}
+		p, err := strconv.ParseFloat(probability, 64)
+		if err != nil {
+			return record, fmt.Errorf("invalid country probability: %w", err)
+		}
+		record.countries = append(record.countries, enricher.Country{CountryId: code, Probability: p})
+	}
+	sort.SliceStable(record.countries, func(i, j int) bool {
+		return record.countries[i].Probability > record.countries[j].Probability
+	})
+	return record, nil
+}
+
+func (d *Dataset) Name() string {
+	return ProviderDataset
+}
+
+// Len returns the number of names in the dataset.
+func (d *Dataset) Len() int {
+	return len(d.records)
+}
+
+func (d *Dataset) lookup(name string) (datasetRecord, error) {
+	record, ok := d.records[names.Normalize(name)]
+	if !ok {
+		return record, fmt.Errorf("%s: %q: %w", ProviderDataset, name, domainErr.ErrNameUnknown)
+	}
+	return record, nil
+}
+
+// Age and Gender ignore countryId, the dataset only holds worldwide statistics.
+func (d *Dataset) Age(ctx context.Context, name string, countryId string) (*enricher.Age, error) {
+	record, err := d.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	if record.age <= 0 {
+		return nil, fmt.Errorf("%s: no age for %q: %w", ProviderDataset, name, domainErr.ErrNameUnknown)
+	}
+	return &enricher.Age{Age: record.age, Count: record.count, Provider: ProviderDataset}, nil
+}
+
+func (d *Dataset) Ages(ctx context.Context, names []string, countryId string) (map[string]*enricher.Age, error) {
+	return fetchEach(ctx, names, countryId, d.Age)
+}
+
+func (d *Dataset) Gender(ctx context.Context, name string, countryId string) (*enricher.Gender, error) {
+	record, err := d.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	if record.maleProbability >= 0.5 {
+		return &enricher.Gender{Gender: "male", Probability: record.maleProbability, Count: record.count, Provider: ProviderDataset}, nil
+	}
+	return &enricher.Gender{Gender: "female", Probability: 1 - record.maleProbability, Count: record.count, Provider: ProviderDataset}, nil
+}
+
+func (d *Dataset) Genders(ctx context.Context, names []string, countryId string) (map[string]*enricher.Gender, error) {
+	return fetchEach(ctx, names, countryId, d.Gender)
+}
+
+func (d *Dataset) Nationality(ctx context.Context, name string) (*enricher.Nationality, error) {
+	record, err := d.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(record.countries) == 0 {
+		return nil, fmt.Errorf("%s: no countries for %q: %w", ProviderDataset, name, domainErr.ErrNameUnknown)
+	}
+	countries := make([]enricher.Country, len(record.countries))
+	copy(countries, record.countries)
+	return &enricher.Nationality{Country: countries, Count: record.count, Provider: ProviderDataset}, nil
+}
+
+func (d *Dataset) Nationalities(ctx context.Context, names []string) (map[string]*enricher.Nationality, error) {
+	return fetchEach(ctx, names, "", func(ctx context.Context, name string, _ string) (*enricher.Nationality, error) {
+		return d.Nationality(ctx, name)
+	})
+}
@@ -0,0 +1,136 @@
+package enricher
+
+import (
+	"context"
+	"errors"
+	domainErr "github.com/Kosodaka/enricher-service/internal/domain/errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadDataset(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		err   bool
+	}{
+		{
+			name:  "valid rows",
+			input: "name,age,male_probability,count,countries\nIvan,41,1,100,UA:0.1|RU:0.3\nDasha,27,0,50,\n",
+		}, {
+			name:  "invalid age",
+			input: "name,age,male_probability,count,countries\nIvan,old,1,100,RU:0.3\n",
+			err:   true,
+		}, {
+			name:  "probability out of range",
+			input: "name,age,male_probability,count,countries\nIvan,41,2,100,RU:0.3\n",
+			err:   true,
+		}, {
+			name:  "invalid country",
+			input: "name,age,male_probability,count,countries\nIvan,41,1,100,RU\n",
+			err:   true,
+		}, {
+			name:  "missing column",
+			input: "name,age,male_probability,count,countries\nIvan,41,1,100\n",
+			err:   true,
+		},
+	}
+	for _, testCases := range cases {
+		t.Run(testCases.name, func(t *testing.T) {
+			_, err := LoadDataset(strings.NewReader(testCases.input))
+			if (err != nil) != testCases.err {
+				t.Errorf("got error %v, want error %v", err, testCases.err)
+			}
+		})
+	}
+}
+
+func TestDataset_Enrich(t *testing.T) {
+	d, err := LoadDataset(strings.NewReader("name,age,male_probability,count,countries\nIvan,41,0.98,100,UA:0.1|RU:0.3\nDasha,27,0.1,50,\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := &Enricher{Age: d, Gender: d, Nationality: d}
+	ctx := context.Background()
+
+	data, err := e.Enrich(ctx, " IVAN ", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !data.Complete() || *data.Age != 41 || *data.Gender != "male" || *data.Nationality != "RU" || data.NationalityCount != 100 {
+		t.Errorf("unexpected data %+v", data)
+	}
+
+	data, err = e.Enrich(ctx, "Dasha", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *data.Gender != "female" || data.GenderProbability != 0.9 || data.Nationality != nil || !errors.Is(data.Err, domainErr.ErrNameUnknown) {
+		t.Errorf("unexpected data %+v", data)
+	}
+
+	data, err = e.Enrich(ctx, "Nobody", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.Age != nil || data.Gender != nil || !errors.Is(data.Err, domainErr.ErrNameUnknown) {
+		t.Errorf("unexpected data %+v", data)
+	}
+}
+
+func TestNewDataset(t *testing.T) {
+	d, err := NewDataset("testdata/names.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Len() == 0 {
+		t.Fatal("test dataset is empty")
+	}
+	if _, err := d.Age(context.Background(), "Oleg", ""); err != nil {
+		t.Errorf("got %v, want an answer for a common name", err)
+	}
+	if _, err := NewDataset("/nonexistent/names.csv"); err == nil {
+		t.Error("got no error for a missing dataset file")
+	}
+	if _, err := NewDataset(""); !errors.Is(err, ErrNoDataset) {
+		t.Errorf("got %v, want %v", err, ErrNoDataset)
+	}
+}
+
+func TestWithFallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	// The dataset answers only when it is given a file, the repository holds no real one.
+	if _, err := NewEnricher(WithFallback(testConfig{url: server.URL}, ProviderDataset)); !errors.Is(err, ErrNoDataset) {
+		t.Fatalf("got %v, want %v", err, ErrNoDataset)
+	}
+	if _, err := NewEnricher(testConfig{url: server.URL}); err != nil {
+		t.Fatalf("got %v without the dataset in any chain", err)
+	}
+
+	cfg := WithFallback(testConfig{url: server.URL, datasetPath: "testdata/names.csv"}, ProviderDataset)
+	if got, want := cfg.GetNationalityProviders(), []string{ProviderNationalize, ProviderDataset, ProviderStatic}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got, want := WithFallback(cfg, ProviderDataset).GetAgeProviders(), []string{ProviderAgify, ProviderDataset}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	e, err := NewEnricher(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := e.Enrich(context.Background(), "Oleg", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !data.Complete() || data.Err != nil {
+		t.Errorf("got %+v, want the dataset to answer while providers are down", data)
+	}
+}
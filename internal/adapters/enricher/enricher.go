@@ -2,190 +2,336 @@ package enricher
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	domainErr "github.com/Kosodaka/enricher-service/internal/domain/errors"
 	"github.com/Kosodaka/enricher-service/internal/domain/ports/enricher"
-	"net/http"
+	"slices"
 	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	ProviderAgify       = "agify"
+	ProviderGenderize   = "genderize"
+	ProviderNationalize = "nationalize"
+	ProviderStatic      = "static"
+	ProviderDataset     = "dataset"
 )
 
 type Configer interface {
 	GetAgeApiURL() string
 	GetGenderApiURL() string
 	GetNationalityApiURL() string
+	GetRetryAttempts() int
+	GetRetryBaseDelay() time.Duration
+	GetRetryMaxDelay() time.Duration
+	GetBreakerThreshold() int
+	GetBreakerOpenTimeout() time.Duration
+	GetAgeProviders() []string
+	GetGenderProviders() []string
+	GetNationalityProviders() []string
+	GetDefaultAge() int
+	GetDefaultGender() string
+	GetDefaultNationality() string
+	GetDatasetPath() string
+	GetPartialEnrichment() bool
+	GetDailyBudget() int
 }
 
+// Enricher predicts every attribute with the provider (usually a chain) configured for it.
 type Enricher struct {
-	AgeUrl         string `json:"age_url"`
-	GenderUrl      string `json:"gender_url"`
-	NationalityUrl string `json:"nationality_url"`
-	Client         *http.Client
+	Age         enricher.AgeProvider
+	Gender      enricher.GenderProvider
+	Nationality enricher.NationalityProvider
+	// FailFast cancels the requests of the other attributes as soon as one attribute
+	// cannot be predicted, for callers that only accept complete data.
+	FailFast bool
 }
 
-type PersonNationality struct {
-	CountryId   string  `json:"country_id"`
-	Probability float64 `json:"probability"`
-}
-type PersonNationalities struct {
-	Country []PersonNationality `json:"country"`
+// stater is implemented by providers guarded by a circuit breaker.
+type stater interface {
+	Name() string
+	State() string
 }
-type PersonAge struct {
-	Age int `json:"age"`
+
+// NewEnricher builds an enricher over the built-in providers using the chains selected in cfg.
+func NewEnricher(cfg Configer) (*Enricher, error) {
+	registry, err := NewDefaultRegistry(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return registry.Enricher(cfg)
 }
-type PersonGender struct {
-	Gender string `json:"gender"`
+
+// providers returns the members of the attribute chains.
+func (e Enricher) providers() []interface{} {
+	var providers []interface{}
+	for _, p := range []interface{}{e.Age, e.Gender, e.Nationality} {
+		switch chain := p.(type) {
+		case AgeChain:
+			for _, p := range chain {
+				providers = append(providers, p)
+			}
+		case GenderChain:
+			for _, p := range chain {
+				providers = append(providers, p)
+			}
+		case NationalityChain:
+			for _, p := range chain {
+				providers = append(providers, p)
+			}
+		default:
+			providers = append(providers, p)
+		}
+	}
+	return providers
 }
 
-func NewEnricher(cfg Configer) *Enricher {
-	return &Enricher{
-		AgeUrl:         cfg.GetAgeApiURL(),
-		GenderUrl:      cfg.GetGenderApiURL(),
-		NationalityUrl: cfg.GetNationalityApiURL(),
-		Client:         &http.Client{},
+// ProviderStatuses reports the circuit breaker state of every upstream provider in use.
+func (e Enricher) ProviderStatuses() []enricher.ProviderStatus {
+	statuses := []enricher.ProviderStatus{}
+	seen := map[string]struct{}{}
+	for _, p := range e.providers() {
+		s, ok := p.(stater)
+		if !ok {
+			continue
+		}
+		if _, ok := seen[s.Name()]; ok {
+			continue
+		}
+		seen[s.Name()] = struct{}{}
+		statuses = append(statuses, enricher.ProviderStatus{Provider: s.Name(), State: s.State()})
 	}
+	return statuses
 }
-func (e Enricher) Enrich(ctx context.Context, name string) (*enricher.EnrichData, error) {
-	errCh := make(chan error)
-	resCh := make(chan *enricher.EnrichData)
 
-	newCtx, cansel := context.WithCancel(ctx)
-	defer cansel()
+// errNotRequested stands for the result of an attribute the caller skipped.
+var errNotRequested = errors.New("attribute not requested")
 
-	go func() {
-		defer close(errCh)
-		defer close(resCh)
-
-		var (
-			age         *PersonAge
-			gender      *PersonGender
-			nationality *PersonNationality
-			err         error
-		)
+// Enrich asks for all attributes but the skipped ones concurrently and returns once every
+// request has finished. Attributes that could not be predicted are left empty with their
+// status set and their failures collected in a domainErr.EnrichError, so the error is only
+// returned when ctx is done. The raw provider answers are attached to the data.
+func (e Enricher) Enrich(ctx context.Context, name string, countryId string, skip ...string) (*enricher.EnrichData, error) {
+	var (
+		age            *enricher.Age
+		gender         *enricher.Gender
+		nationality    *enricher.Nationality
+		ageErr         error
+		genderErr      error
+		nationalityErr error
+	)
 
-		w := &sync.WaitGroup{}
-		w.Add(3)
+	fanCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	fanCtx, responses := withRecorder(fanCtx)
+	// failed is set by the first attribute that fails, the others are cancelled then.
+	var failed atomic.Bool
+	fail := func(err *error) {
+		if *err != nil && e.FailFast && failed.CompareAndSwap(false, true) {
+			cancel()
+			// The error of the first failure is kept even when it raced with the cancellation.
+			*err = &firstFailure{*err}
+		}
+	}
 
+	w := &sync.WaitGroup{}
+	ask := func(attribute string, err *error, predict func()) {
+		if slices.Contains(skip, attribute) {
+			*err = errNotRequested
+			return
+		}
+		w.Add(1)
 		go func() {
 			defer w.Done()
-			age, err = e.getAge(newCtx, name)
-			if err != nil {
-				errCh <- err
-				return
-			}
+			predict()
+			fail(err)
 		}()
+	}
+	ask(enricher.AttributeAge, &ageErr, func() {
+		age, ageErr = e.Age.Age(fanCtx, name, countryId)
+	})
+	ask(enricher.AttributeGender, &genderErr, func() {
+		gender, genderErr = e.Gender.Gender(fanCtx, name, countryId)
+	})
+	ask(enricher.AttributeNationality, &nationalityErr, func() {
+		nationality, nationalityErr = e.Nationality.Nationality(fanCtx, name)
+		if nationalityErr == nil && (nationality == nil || len(nationality.Country) == 0) {
+			nationalityErr = fmt.Errorf("no nationality: %w", domainErr.ErrNameUnknown)
+		}
+	})
+	w.Wait()
 
-		go func() {
-			defer w.Done()
-			gender, err = e.getGender(newCtx, name)
-			if err != nil {
-				errCh <- err
-				return
-			}
-		}()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	data := newEnrichData(age, ageErr, gender, genderErr, nationality, nationalityErr)
+	data.Responses = responses.list()
+	return data, nil
+}
 
-		go func() {
-			defer w.Done()
-			nationalities, err := e.getNationality(newCtx, name)
-			if err != nil {
-				errCh <- err
-				return
-			}
-			if len(nationalities.Country) == 0 {
-				errCh <- fmt.Errorf("no nationality")
-				return
-			}
-			// The first nationality from api url has the most probability
-			nationality = &PersonNationality{CountryId: nationalities.Country[0].CountryId, Probability: nationalities.Country[0].Probability}
-		}()
+// firstFailure marks the error that made Enrich cancel the other attributes.
+type firstFailure struct {
+	err error
+}
 
-		w.Wait()
-		if age != nil && gender != nil && nationality != nil {
-			resCh <- &enricher.EnrichData{
-				Age:         age.Age,
-				Gender:      gender.Gender,
-				Nationality: nationality.CountryId,
-			}
+func (e *firstFailure) Error() string { return e.err.Error() }
+func (e *firstFailure) Unwrap() error { return e.err }
 
+// EnrichBatch enriches all names with one batch call per attribute. A failure for one
+// name is reported in its BatchResult and does not affect the others.
+func (e Enricher) EnrichBatch(ctx context.Context, names []string, countryId string) (map[string]enricher.BatchResult, error) {
+	unique := make([]string, 0, len(names))
+	seen := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		if _, ok := seen[name]; ok {
+			continue
 		}
+		seen[name] = struct{}{}
+		unique = append(unique, name)
+	}
+
+	var (
+		ages           map[string]*enricher.Age
+		genders        map[string]*enricher.Gender
+		nationalities  map[string]*enricher.Nationality
+		ageErr         error
+		genderErr      error
+		nationalityErr error
+	)
 
+	w := &sync.WaitGroup{}
+	w.Add(3)
+	go func() {
+		defer w.Done()
+		ages, ageErr = e.Age.Ages(ctx, unique, countryId)
+	}()
+	go func() {
+		defer w.Done()
+		genders, genderErr = e.Gender.Genders(ctx, unique, countryId)
+	}()
+	go func() {
+		defer w.Done()
+		nationalities, nationalityErr = e.Nationality.Nationalities(ctx, unique)
 	}()
+	w.Wait()
 
-	select {
-	case <-ctx.Done():
-		return nil, fmt.Errorf("error whith enricher")
-	case err := <-errCh:
+	if err := ctx.Err(); err != nil {
 		return nil, err
-	case res := <-resCh:
-		return res, nil
 	}
 
+	results := make(map[string]enricher.BatchResult, len(unique))
+	for _, name := range unique {
+		age, gender, nationality := ages[name], genders[name], nationalities[name]
+		data := newEnrichData(
+			age, missing(age == nil, ageErr),
+			gender, missing(gender == nil, genderErr),
+			nationality, missing(nationality == nil, nationalityErr),
+		)
+		results[name] = enricher.BatchResult{Data: data, Err: data.Err}
+	}
+	return results, nil
 }
 
-// Come to api with request on env:AGE_API_URL and get Age
-func (e Enricher) getAge(ctx context.Context, name string) (*PersonAge, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s?name=%s", e.AgeUrl, name), nil)
-	if err != nil {
-		return nil, err
+// missing returns the batch error for a name left unanswered.
+func missing(unanswered bool, err error) error {
+	if !unanswered {
+		return nil
 	}
-	resp, err := e.Client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("errror to get age")
+	if err == nil {
+		return domainErr.ErrNameUnknown
 	}
-	defer resp.Body.Close()
-	age := &PersonAge{}
-	err = json.NewDecoder(resp.Body).Decode(&age)
-	if err != nil {
-		return nil, fmt.Errorf("%s: error to decode", err)
-	}
-
-	return age, nil
+	return err
 }
 
-// Come to api with request on env:GENDER_API_URL and get gender
-func (e Enricher) getGender(ctx context.Context, name string) (*PersonGender, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s?name=%s", e.GenderUrl, name), nil)
-	if err != nil {
-		return nil, err
+func newEnrichData(
+	age *enricher.Age, ageErr error,
+	gender *enricher.Gender, genderErr error,
+	nationalities *enricher.Nationality, nationalityErr error,
+) *enricher.EnrichData {
+	if nationalityErr == nil && (nationalities == nil || len(nationalities.Country) == 0) {
+		nationalityErr = fmt.Errorf("no nationality: %w", domainErr.ErrNameUnknown)
 	}
-	resp, err := e.Client.Do(req)
-	if err != nil {
-		return nil, err
+	data := &enricher.EnrichData{
+		AgeStatus:         status(ageErr),
+		GenderStatus:      status(genderErr),
+		NationalityStatus: status(nationalityErr),
 	}
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("errror to get gender")
+	var errs domainErr.EnrichError
+	for _, attr := range []struct {
+		name string
+		err  error
+	}{
+		{enricher.AttributeAge, ageErr},
+		{enricher.AttributeGender, genderErr},
+		{enricher.AttributeNationality, nationalityErr},
+	} {
+		if attr.err != nil && !skipped(attr.err) && attr.err != errNotRequested {
+			errs = append(errs, attributeError(attr.name, attr.err))
+		}
 	}
-	defer resp.Body.Close()
-	gender := &PersonGender{}
-	err = json.NewDecoder(resp.Body).Decode(&gender)
-	if err != nil {
-		return nil, err
+	if len(errs) > 0 {
+		data.Err = errs
 	}
-
-	return gender, nil
-}
-
-// Come to api with request on env:NATIONALITY_API_URL get nationality
-func (e Enricher) getNationality(ctx context.Context, name string) (*PersonNationalities, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s?name=%s", e.NationalityUrl, name), nil)
-	if err != nil {
-		return nil, err
+	if ageErr == nil {
+		data.Age = &age.Age
+		data.AgeCount = age.Count
+		data.AgeCountryId = age.CountryId
+		data.AgeSource = age.Provider
 	}
-	resp, err := e.Client.Do(req)
-	if err != nil {
-		return nil, err
+	if genderErr == nil {
+		data.Gender = &gender.Gender
+		data.GenderProbability = gender.Probability
+		data.GenderCount = gender.Count
+		data.GenderCountryId = gender.CountryId
+		data.GenderSource = gender.Provider
 	}
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("errror to get nationality")
+	if nationalityErr == nil {
+		// The first nationality from api url has the most probability
+		top := nationalities.Country[0]
+		data.Nationality = &top.CountryId
+		data.NationalityProbability = top.Probability
+		data.NationalityCount = nationalities.Count
+		data.Nationalities = nationalities.Country
+		data.NationalitySource = nationalities.Provider
 	}
-	defer resp.Body.Close()
-	nationalities := &PersonNationalities{}
-	err = json.NewDecoder(resp.Body).Decode(&nationalities)
-	if err != nil {
-		return nil, err
+	return data
+}
+
+// attributeError names the attribute and the provider that failed for it.
+func attributeError(attribute string, err error) *domainErr.ProviderError {
+	var first *firstFailure
+	if errors.As(err, &first) {
+		err = first.err
+	}
+	var providerErr *domainErr.ProviderError
+	if errors.As(err, &providerErr) {
+		attributeErr := *providerErr
+		attributeErr.Attribute = attribute
+		return &attributeErr
 	}
+	return &domainErr.ProviderError{Attribute: attribute, Provider: "unknown", Err: err}
+}
+
+// skipped reports whether the request was cancelled by Enrich after another attribute failed.
+func skipped(err error) bool {
+	var first *firstFailure
+	return errors.Is(err, context.Canceled) && !errors.As(err, &first)
+}
 
-	return nationalities, nil
+func status(err error) string {
+	switch {
+	case err == nil:
+		return enricher.StatusOk
+	case err == errNotRequested:
+		return enricher.StatusNotRequested
+	case skipped(err):
+		return enricher.StatusSkipped
+	case errors.Is(err, domainErr.ErrNameUnknown):
+		return enricher.StatusUnknown
+	default:
+		return enricher.StatusProviderError
+	}
 }
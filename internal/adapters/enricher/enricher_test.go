@@ -0,0 +1,540 @@
+package enricher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	domainErr "github.com/Kosodaka/enricher-service/internal/domain/errors"
+	"github.com/Kosodaka/enricher-service/internal/domain/ports/enricher"
+	"github.com/Kosodaka/enricher-service/pkg/ptr"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testConfig struct {
+	url         string
+	strict      bool
+	budget      int
+	datasetPath string
+}
+
+func (c testConfig) GetAgeApiURL() string                 { return c.url + "/age" }
+func (c testConfig) GetGenderApiURL() string              { return c.url + "/gender" }
+func (c testConfig) GetNationalityApiURL() string         { return c.url + "/nationality" }
+func (c testConfig) GetRetryAttempts() int                { return 3 }
+func (c testConfig) GetRetryBaseDelay() time.Duration     { return time.Millisecond }
+func (c testConfig) GetRetryMaxDelay() time.Duration      { return 10 * time.Millisecond }
+func (c testConfig) GetBreakerThreshold() int             { return 2 }
+func (c testConfig) GetBreakerOpenTimeout() time.Duration { return time.Hour }
+func (c testConfig) GetAgeProviders() []string            { return []string{ProviderAgify} }
+func (c testConfig) GetGenderProviders() []string         { return []string{ProviderGenderize} }
+func (c testConfig) GetNationalityProviders() []string {
+	return []string{ProviderNationalize, ProviderStatic}
+}
+func (c testConfig) GetDefaultAge() int            { return 0 }
+func (c testConfig) GetDefaultGender() string      { return "" }
+func (c testConfig) GetDefaultNationality() string { return "KZ" }
+func (c testConfig) GetDatasetPath() string        { return c.datasetPath }
+func (c testConfig) GetPartialEnrichment() bool    { return !c.strict }
+func (c testConfig) GetDailyBudget() int           { return c.budget }
+
+func TestEnricher_EnrichBatch(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		names := r.URL.Query()["name[]"]
+		if len(names) > batchSize {
+			t.Errorf("got %d names in one request, want at most %d", len(names), batchSize)
+		}
+		resp := make([]map[string]interface{}, 0, len(names))
+		for _, name := range names {
+			item := map[string]interface{}{"name": name}
+			switch r.URL.Path {
+			case "/age":
+				item["age"] = len(name)
+			case "/gender":
+				item["gender"] = "male"
+			case "/nationality":
+				if name != "Nobody" {
+					item["country"] = []PersonNationality{{CountryId: "RU", Probability: 0.5}}
+				}
+			}
+			resp = append(resp, item)
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	names := []string{"Nobody"}
+	for i := 0; i < 22; i++ {
+		names = append(names, fmt.Sprintf("Name%d", i))
+	}
+
+	e, err := NewEnricher(testConfig{url: server.URL})
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+	results, err := e.EnrichBatch(context.Background(), names, "")
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+	if got := requests.Load(); got != 9 {
+		t.Errorf("got %d requests, want 9", got)
+	}
+	if len(results) != len(names) {
+		t.Fatalf("got %d results, want %d", len(results), len(names))
+	}
+	if res := results["Nobody"]; res.Err != nil || *res.Data.Nationality != "KZ" {
+		t.Errorf("got %+v, want static nationality KZ", res)
+	}
+	if res := results["Name10"]; res.Err != nil || *res.Data.Age != 6 || *res.Data.Nationality != "RU" {
+		t.Errorf("got %+v, want age 6 from RU", res)
+	}
+}
+
+func TestEnricher_EnrichBatchErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gender" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		resp := []map[string]interface{}{}
+		for _, name := range r.URL.Query()["name[]"] {
+			item := map[string]interface{}{"name": name}
+			if r.URL.Path == "/age" && name != "Nobody" {
+				item["age"] = 40
+			}
+			resp = append(resp, item)
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	e, err := NewEnricher(testConfig{url: server.URL})
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+	results, err := e.EnrichBatch(context.Background(), []string{"Oleg", "Nobody"}, "")
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+	// Each name carries its own failure, the answered attributes are kept.
+	if res := results["Oleg"]; !errors.Is(res.Err, domainErr.ErrProviderUnavailable) || res.Data == nil || *res.Data.Age != 40 {
+		t.Errorf("got %+v, want the gender provider failure and age 40", res)
+	}
+	if res := results["Nobody"]; !errors.Is(res.Err, domainErr.ErrNameUnknown) || res.Data.Age != nil {
+		t.Errorf("got %+v, want an unknown age", res)
+	}
+}
+
+func TestEnricher_getRetriesAndBreaker(t *testing.T) {
+	var requests atomic.Int32
+	var failFirst atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if failFirst.Add(-1) >= 0 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(PersonAge{Name: "Oleg", Age: ptr.To(60)})
+	}))
+	defer server.Close()
+	agify := NewAgify(testConfig{url: server.URL})
+
+	failFirst.Store(2)
+	age, err := agify.Age(context.Background(), "Oleg", "")
+	if err != nil || age.Age != 60 {
+		t.Fatalf("got %v, %v, want age 60 after retries", age, err)
+	}
+	if got := requests.Load(); got != 3 {
+		t.Errorf("got %d requests, want 3", got)
+	}
+
+	// Two calls exhausting their retries open the breaker, the third one fails fast.
+	failFirst.Store(6)
+	requests.Store(0)
+	for i := 0; i < 3; i++ {
+		_, err = agify.Age(context.Background(), "Oleg", "")
+		if !errors.Is(err, domainErr.ErrProviderUnavailable) {
+			t.Fatalf("got %v, want %v", err, domainErr.ErrProviderUnavailable)
+		}
+	}
+	if got := requests.Load(); got != 6 {
+		t.Errorf("got %d requests, want 6", got)
+	}
+	if state := agify.State(); state != StateOpen {
+		t.Errorf("got state %s, want %s", state, StateOpen)
+	}
+}
+
+// stubApis stands in for the three providers. A handler returning false blocks the
+// request until the client goes away and records that it did.
+type stubApis struct {
+	age, gender, nationality func(w http.ResponseWriter) bool
+	abandoned                atomic.Int32
+}
+
+func (s *stubApis) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	handler := map[string]func(w http.ResponseWriter) bool{
+		"/age":         s.age,
+		"/gender":      s.gender,
+		"/nationality": s.nationality,
+	}[r.URL.Path]
+	if handler(w) {
+		return
+	}
+	select {
+	case <-r.Context().Done():
+		s.abandoned.Add(1)
+	case <-time.After(5 * time.Second):
+		w.WriteHeader(http.StatusGatewayTimeout)
+	}
+}
+
+func answer(body interface{}) func(w http.ResponseWriter) bool {
+	return func(w http.ResponseWriter) bool {
+		json.NewEncoder(w).Encode(body)
+		return true
+	}
+}
+
+func unavailable(w http.ResponseWriter) bool {
+	w.WriteHeader(http.StatusServiceUnavailable)
+	return true
+}
+
+func hang(w http.ResponseWriter) bool {
+	return false
+}
+
+func TestEnricher_Enrich(t *testing.T) {
+	cases := []struct {
+		name      string
+		strict    bool
+		apis      *stubApis
+		statuses  [3]string
+		providers []string
+		wantErr   error
+		abandoned int32
+	}{
+		{
+			name: "all attributes predicted",
+			apis: &stubApis{
+				age:         answer(PersonAge{Age: ptr.To(40), Count: 10}),
+				gender:      answer(PersonGender{Gender: ptr.To("female"), Probability: 0.9}),
+				nationality: answer(PersonNationalities{Country: []PersonNationality{{CountryId: "RU", Probability: 0.5}}}),
+			},
+			statuses: [3]string{enricher.StatusOk, enricher.StatusOk, enricher.StatusOk},
+		}, {
+			name: "failures of every provider are collected",
+			apis: &stubApis{
+				age:         unavailable,
+				gender:      answer(PersonGender{}),
+				nationality: unavailable,
+			},
+			// The static provider stands in for the nationality.
+			statuses:  [3]string{enricher.StatusProviderError, enricher.StatusUnknown, enricher.StatusOk},
+			providers: []string{ProviderAgify, ProviderGenderize},
+			wantErr:   domainErr.ErrProviderUnavailable,
+		}, {
+			name:   "first failure cancels the other attributes",
+			strict: true,
+			apis: &stubApis{
+				age:         hang,
+				gender:      answer(PersonGender{}),
+				nationality: hang,
+			},
+			statuses:  [3]string{enricher.StatusSkipped, enricher.StatusUnknown, enricher.StatusSkipped},
+			providers: []string{ProviderGenderize},
+			wantErr:   domainErr.ErrNameUnknown,
+			abandoned: 2,
+		},
+	}
+	for _, testCases := range cases {
+		t.Run(testCases.name, func(t *testing.T) {
+			server := httptest.NewServer(testCases.apis)
+			defer server.Close()
+			e, err := NewEnricher(testConfig{url: server.URL, strict: testCases.strict})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := e.Enrich(context.Background(), "Olga", "")
+			if err != nil {
+				t.Fatalf("got error %v", err)
+			}
+			if got := [3]string{data.AgeStatus, data.GenderStatus, data.NationalityStatus}; got != testCases.statuses {
+				t.Errorf("got statuses %v, want %v", got, testCases.statuses)
+			}
+			if testCases.wantErr == nil {
+				if data.Err != nil {
+					t.Errorf("got error %v", data.Err)
+				}
+				return
+			}
+			var enrichErr domainErr.EnrichError
+			if !errors.As(data.Err, &enrichErr) {
+				t.Fatalf("got %T, want %T", data.Err, enrichErr)
+			}
+			if got := enrichErr.Providers(); !reflect.DeepEqual(got, testCases.providers) {
+				t.Errorf("got failed providers %v, want %v", got, testCases.providers)
+			}
+			if !errors.Is(data.Err, testCases.wantErr) {
+				t.Errorf("got %v, want %v", data.Err, testCases.wantErr)
+			}
+			if testCases.abandoned > 0 {
+				// The handlers notice the cancellation asynchronously.
+				deadline := time.Now().Add(time.Second)
+				for testCases.apis.abandoned.Load() < testCases.abandoned && time.Now().Before(deadline) {
+					time.Sleep(time.Millisecond)
+				}
+				if got := testCases.apis.abandoned.Load(); got != testCases.abandoned {
+					t.Errorf("got %d cancelled requests, want %d", got, testCases.abandoned)
+				}
+			}
+		})
+	}
+}
+
+func TestEnricher_EnrichSkip(t *testing.T) {
+	apis := &stubApis{
+		age:         answer(PersonAge{Age: ptr.To(40), Count: 10}),
+		gender:      hang,
+		nationality: hang,
+	}
+	server := httptest.NewServer(apis)
+	defer server.Close()
+	e, err := NewEnricher(testConfig{url: server.URL, strict: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := e.Enrich(context.Background(), "Olga", "", enricher.AttributeGender, enricher.AttributeNationality)
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+	want := [3]string{enricher.StatusOk, enricher.StatusNotRequested, enricher.StatusNotRequested}
+	if got := [3]string{data.AgeStatus, data.GenderStatus, data.NationalityStatus}; got != want {
+		t.Errorf("got statuses %v, want %v", got, want)
+	}
+	if data.Err != nil || data.Failed() {
+		t.Errorf("got error %v, want skipped attributes not to fail", data.Err)
+	}
+	if data.AgeSource != ProviderAgify || data.GenderSource != "" {
+		t.Errorf("got sources %q and %q, want the answering provider only", data.AgeSource, data.GenderSource)
+	}
+}
+
+func TestEnricher_EnrichRecordsResponses(t *testing.T) {
+	apis := &stubApis{
+		age: func(w http.ResponseWriter) bool {
+			w.Header().Set("X-Rate-Limit-Remaining", "99")
+			w.Header().Set("Set-Cookie", "session=secret")
+			return answer(PersonAge{Age: ptr.To(40), Count: 10})(w)
+		},
+		gender:      unavailable,
+		nationality: answer(PersonNationalities{Count: 10, Country: []PersonNationality{{CountryId: "RU", Probability: 0.5}}}),
+	}
+	server := httptest.NewServer(apis)
+	defer server.Close()
+	e, err := NewEnricher(testConfig{url: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := e.Enrich(context.Background(), "Olga", "")
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+	got := map[string][]int{}
+	for _, resp := range data.Responses {
+		got[resp.Provider] = append(got[resp.Provider], resp.Status)
+		if resp.Provider != ProviderAgify {
+			continue
+		}
+		if !strings.Contains(resp.Body, `"age":40`) || !strings.Contains(resp.URL, "name=Olga") {
+			t.Errorf("got agify response %+v, want its body and url", resp)
+		}
+		if want := map[string]string{"X-Rate-Limit-Remaining": "99", "Content-Type": "text/plain; charset=utf-8"}; !reflect.DeepEqual(without(resp.Headers, "Date"), want) {
+			t.Errorf("got headers %v, want %v", resp.Headers, want)
+		}
+	}
+	want := map[string][]int{
+		ProviderAgify:       {http.StatusOK},
+		ProviderGenderize:   {http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+		ProviderNationalize: {http.StatusOK},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got response statuses %v, want %v", got, want)
+	}
+}
+
+// without returns a copy of headers without key.
+func without(headers map[string]string, key string) map[string]string {
+	rest := map[string]string{}
+	for k, v := range headers {
+		if k != key {
+			rest[k] = v
+		}
+	}
+	return rest
+}
+
+func TestAgify_AgeLocalized(t *testing.T) {
+	var countries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		countryId := r.URL.Query().Get("country_id")
+		countries = append(countries, countryId)
+		if countryId == "KZ" {
+			json.NewEncoder(w).Encode(PersonAge{})
+			return
+		}
+		json.NewEncoder(w).Encode(PersonAge{Age: ptr.To(33), Count: 5})
+	}))
+	defer server.Close()
+	p := NewAgify(testConfig{url: server.URL})
+
+	cases := []struct {
+		name      string
+		countryId string
+		want      *enricher.Age
+		countries []string
+	}{
+		{
+			name:      "known in the country",
+			countryId: "RU",
+			want:      &enricher.Age{Age: 33, Count: 5, CountryId: "RU", Provider: ProviderAgify},
+			countries: []string{"RU"},
+		}, {
+			name:      "unknown in the country falls back to worldwide",
+			countryId: "KZ",
+			want:      &enricher.Age{Age: 33, Count: 5, Provider: ProviderAgify},
+			countries: []string{"KZ", ""},
+		}, {
+			name:      "no country hint",
+			want:      &enricher.Age{Age: 33, Count: 5, Provider: ProviderAgify},
+			countries: []string{""},
+		},
+	}
+	for _, testCases := range cases {
+		t.Run(testCases.name, func(t *testing.T) {
+			countries = nil
+			got, err := p.Age(context.Background(), "Aidar", testCases.countryId)
+			if err != nil {
+				t.Fatalf("got error %v", err)
+			}
+			if !reflect.DeepEqual(got, testCases.want) {
+				t.Errorf("got %+v, want %+v", got, testCases.want)
+			}
+			if !reflect.DeepEqual(countries, testCases.countries) {
+				t.Errorf("got requests for %q, want %q", countries, testCases.countries)
+			}
+		})
+	}
+}
+
+func TestAgify_AgeEscapesName(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.Query().Get("name")
+		json.NewEncoder(w).Encode(PersonAge{Age: ptr.To(33)})
+	}))
+	defer server.Close()
+
+	name := "Anne-Marie O'Brien&country_id=US"
+	if _, err := NewAgify(testConfig{url: server.URL}).Age(context.Background(), name, ""); err != nil {
+		t.Fatalf("got error %v", err)
+	}
+	if got != name {
+		t.Errorf("got name %q, want %q", got, name)
+	}
+}
+
+func TestEnricher_EnrichCancelled(t *testing.T) {
+	apis := &stubApis{age: hang, gender: hang, nationality: hang}
+	server := httptest.NewServer(apis)
+	defer server.Close()
+	e, err := NewEnricher(testConfig{url: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	data, err := e.Enrich(ctx, "Olga", "")
+	if !errors.Is(err, context.DeadlineExceeded) || data != nil {
+		t.Fatalf("got %v, %v, want %v", data, err, context.DeadlineExceeded)
+	}
+	// Cancelled requests do not count as provider failures.
+	for _, p := range e.ProviderStatuses() {
+		if p.State != StateClosed {
+			t.Errorf("got %s %s, want cancellation not to count as a failure", p.Provider, p.State)
+		}
+	}
+}
+
+func TestApiClient_providerError(t *testing.T) {
+	cases := []struct {
+		name       string
+		handler    func(w http.ResponseWriter)
+		err        error
+		status     int
+		retryAfter time.Duration
+	}{
+		{
+			name: "rate limited",
+			handler: func(w http.ResponseWriter) {
+				w.Header().Set("X-Rate-Limit-Reset", "120")
+				w.WriteHeader(http.StatusTooManyRequests)
+			},
+			err:        domainErr.ErrProviderRateLimited,
+			status:     http.StatusTooManyRequests,
+			retryAfter: 2 * time.Minute,
+		}, {
+			name:    "server error",
+			handler: func(w http.ResponseWriter) { w.WriteHeader(http.StatusBadGateway) },
+			err:     domainErr.ErrProviderUnavailable,
+			status:  http.StatusBadGateway,
+		}, {
+			name:    "rejected request",
+			handler: func(w http.ResponseWriter) { w.WriteHeader(http.StatusUnprocessableEntity) },
+			err:     domainErr.ErrProviderBadResponse,
+			status:  http.StatusUnprocessableEntity,
+		}, {
+			name:    "malformed body",
+			handler: func(w http.ResponseWriter) { w.Write([]byte("<html>")) },
+			err:     domainErr.ErrProviderBadResponse,
+		}, {
+			name:    "unknown name",
+			handler: func(w http.ResponseWriter) { json.NewEncoder(w).Encode(PersonAge{Name: "Xyzzy"}) },
+			err:     domainErr.ErrNameUnknown,
+		},
+	}
+	for _, testCases := range cases {
+		t.Run(testCases.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				testCases.handler(w)
+			}))
+			defer server.Close()
+
+			_, err := NewAgify(testConfig{url: server.URL}).Age(context.Background(), "Xyzzy", "")
+			if !errors.Is(err, testCases.err) {
+				t.Fatalf("got %v, want %v", err, testCases.err)
+			}
+			var providerErr *domainErr.ProviderError
+			if !errors.As(err, &providerErr) {
+				t.Fatalf("got %T, want %T", err, providerErr)
+			}
+			if providerErr.Provider != ProviderAgify || providerErr.Status != testCases.status || providerErr.RetryAfter != testCases.retryAfter {
+				t.Errorf("got %+v", providerErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,331 @@
+package enricher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	domainErr "github.com/Kosodaka/enricher-service/internal/domain/errors"
+	"github.com/Kosodaka/enricher-service/internal/domain/ports/enricher"
+	"net/url"
+	"sync"
+)
+
+// batchSize is the maximum number of names the providers accept in one request.
+const batchSize = 10
+
+type PersonNationality struct {
+	CountryId   string  `json:"country_id"`
+	Probability float64 `json:"probability"`
+}
+type PersonNationalities struct {
+	Name    string              `json:"name"`
+	Count   int                 `json:"count"`
+	Country []PersonNationality `json:"country"`
+}
+
+// Age and Gender are null for names the provider knows nothing about.
+type PersonAge struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+	Age   *int   `json:"age"`
+}
+type PersonGender struct {
+	Name        string  `json:"name"`
+	Count       int     `json:"count"`
+	Gender      *string `json:"gender"`
+	Probability float64 `json:"probability"`
+}
+
+// Agify predicts age through env:AGE_API_URL.
+type Agify struct {
+	*apiClient
+}
+
+func NewAgify(cfg Configer) *Agify {
+	return &Agify{newApiClient(ProviderAgify, cfg.GetAgeApiURL(), cfg)}
+}
+
+func (p *Agify) Age(ctx context.Context, name string, countryId string) (*enricher.Age, error) {
+	return localized(countryId, func(countryId string) (*enricher.Age, error) {
+		age := &PersonAge{}
+		if err := p.get(ctx, withCountry(url.Values{"name": {name}}, countryId), age); err != nil {
+			return nil, fmt.Errorf("error to get age: %w", err)
+		}
+		if age.Age == nil {
+			return nil, &domainErr.ProviderError{Provider: ProviderAgify, Err: domainErr.ErrNameUnknown}
+		}
+		return &enricher.Age{Age: *age.Age, Count: age.Count, CountryId: countryId, Provider: ProviderAgify}, nil
+	})
+}
+
+func (p *Agify) Ages(ctx context.Context, names []string, countryId string) (map[string]*enricher.Age, error) {
+	return localizedMany(ctx, names, countryId, p.ages)
+}
+
+func (p *Agify) ages(ctx context.Context, names []string, countryId string) (map[string]*enricher.Age, error) {
+	return fetchChunks(ctx, names, func(ctx context.Context, chunk []string) (map[string]*enricher.Age, error) {
+		ages := []PersonAge{}
+		if err := p.get(ctx, withCountry(batchQuery(chunk), countryId), &ages); err != nil {
+			return nil, fmt.Errorf("error to get ages: %w", err)
+		}
+		if len(ages) != len(chunk) {
+			return nil, fmt.Errorf("got %d ages for %d names", len(ages), len(chunk))
+		}
+		res := make(map[string]*enricher.Age, len(chunk))
+		for i, name := range chunk {
+			if ages[i].Age != nil {
+				res[name] = &enricher.Age{Age: *ages[i].Age, Count: ages[i].Count, CountryId: countryId, Provider: ProviderAgify}
+			}
+		}
+		return res, nil
+	})
+}
+
+// Genderize predicts gender through env:GENDER_API_URL.
+type Genderize struct {
+	*apiClient
+}
+
+func NewGenderize(cfg Configer) *Genderize {
+	return &Genderize{newApiClient(ProviderGenderize, cfg.GetGenderApiURL(), cfg)}
+}
+
+func (p *Genderize) Gender(ctx context.Context, name string, countryId string) (*enricher.Gender, error) {
+	return localized(countryId, func(countryId string) (*enricher.Gender, error) {
+		gender := &PersonGender{}
+		if err := p.get(ctx, withCountry(url.Values{"name": {name}}, countryId), gender); err != nil {
+			return nil, fmt.Errorf("error to get gender: %w", err)
+		}
+		if gender.Gender == nil {
+			return nil, &domainErr.ProviderError{Provider: ProviderGenderize, Err: domainErr.ErrNameUnknown}
+		}
+		return toGender(gender, countryId), nil
+	})
+}
+
+func (p *Genderize) Genders(ctx context.Context, names []string, countryId string) (map[string]*enricher.Gender, error) {
+	return localizedMany(ctx, names, countryId, p.genders)
+}
+
+func (p *Genderize) genders(ctx context.Context, names []string, countryId string) (map[string]*enricher.Gender, error) {
+	return fetchChunks(ctx, names, func(ctx context.Context, chunk []string) (map[string]*enricher.Gender, error) {
+		genders := []PersonGender{}
+		if err := p.get(ctx, withCountry(batchQuery(chunk), countryId), &genders); err != nil {
+			return nil, fmt.Errorf("error to get genders: %w", err)
+		}
+		if len(genders) != len(chunk) {
+			return nil, fmt.Errorf("got %d genders for %d names", len(genders), len(chunk))
+		}
+		res := make(map[string]*enricher.Gender, len(chunk))
+		for i, name := range chunk {
+			if genders[i].Gender != nil {
+				res[name] = toGender(&genders[i], countryId)
+			}
+		}
+		return res, nil
+	})
+}
+
+// Nationalize predicts nationality through env:NATIONALITY_API_URL.
+type Nationalize struct {
+	*apiClient
+}
+
+func NewNationalize(cfg Configer) *Nationalize {
+	return &Nationalize{newApiClient(ProviderNationalize, cfg.GetNationalityApiURL(), cfg)}
+}
+
+func (p *Nationalize) Nationality(ctx context.Context, name string) (*enricher.Nationality, error) {
+	nationalities := &PersonNationalities{}
+	if err := p.get(ctx, url.Values{"name": {name}}, nationalities); err != nil {
+		return nil, fmt.Errorf("error to get nationality: %w", err)
+	}
+	if len(nationalities.Country) == 0 {
+		return nil, &domainErr.ProviderError{Provider: ProviderNationalize, Err: domainErr.ErrNameUnknown}
+	}
+	return toNationality(nationalities), nil
+}
+
+func (p *Nationalize) Nationalities(ctx context.Context, names []string) (map[string]*enricher.Nationality, error) {
+	return fetchChunks(ctx, names, func(ctx context.Context, chunk []string) (map[string]*enricher.Nationality, error) {
+		nationalities := []PersonNationalities{}
+		if err := p.get(ctx, batchQuery(chunk), &nationalities); err != nil {
+			return nil, fmt.Errorf("error to get nationalities: %w", err)
+		}
+		if len(nationalities) != len(chunk) {
+			return nil, fmt.Errorf("got %d nationalities for %d names", len(nationalities), len(chunk))
+		}
+		res := make(map[string]*enricher.Nationality, len(chunk))
+		for i, name := range chunk {
+			if len(nationalities[i].Country) > 0 {
+				res[name] = toNationality(&nationalities[i])
+			}
+		}
+		return res, nil
+	})
+}
+
+func toGender(g *PersonGender, countryId string) *enricher.Gender {
+	return &enricher.Gender{Gender: *g.Gender, Probability: g.Probability, Count: g.Count, CountryId: countryId, Provider: ProviderGenderize}
+}
+
+func toNationality(n *PersonNationalities) *enricher.Nationality {
+	res := &enricher.Nationality{Country: make([]enricher.Country, 0, len(n.Country)), Count: n.Count, Provider: ProviderNationalize}
+	for _, c := range n.Country {
+		res.Country = append(res.Country, enricher.Country{CountryId: c.CountryId, Probability: c.Probability})
+	}
+	return res
+}
+
+// Static answers every name with configured default values. It is meant to be the
+// last provider of a chain.
+type Static struct {
+	age         int
+	gender      string
+	nationality string
+}
+
+func NewStatic(cfg Configer) *Static {
+	return &Static{
+		age:         cfg.GetDefaultAge(),
+		gender:      cfg.GetDefaultGender(),
+		nationality: cfg.GetDefaultNationality(),
+	}
+}
+
+func (p *Static) Name() string {
+	return ProviderStatic
+}
+
+func (p *Static) Age(ctx context.Context, name string, countryId string) (*enricher.Age, error) {
+	if p.age <= 0 {
+		return nil, fmt.Errorf("%s: no default age: %w", ProviderStatic, domainErr.ErrNameUnknown)
+	}
+	return &enricher.Age{Age: p.age, Provider: ProviderStatic}, nil
+}
+
+func (p *Static) Ages(ctx context.Context, names []string, countryId string) (map[string]*enricher.Age, error) {
+	return fetchEach(ctx, names, countryId, p.Age)
+}
+
+func (p *Static) Gender(ctx context.Context, name string, countryId string) (*enricher.Gender, error) {
+	if p.gender == "" {
+		return nil, fmt.Errorf("%s: no default gender: %w", ProviderStatic, domainErr.ErrNameUnknown)
+	}
+	return &enricher.Gender{Gender: p.gender, Probability: 1, Provider: ProviderStatic}, nil
+}
+
+func (p *Static) Genders(ctx context.Context, names []string, countryId string) (map[string]*enricher.Gender, error) {
+	return fetchEach(ctx, names, countryId, p.Gender)
+}
+
+func (p *Static) Nationality(ctx context.Context, name string) (*enricher.Nationality, error) {
+	if p.nationality == "" {
+		return nil, fmt.Errorf("%s: no default nationality: %w", ProviderStatic, domainErr.ErrNameUnknown)
+	}
+	return &enricher.Nationality{Country: []enricher.Country{{CountryId: p.nationality, Probability: 1}}, Provider: ProviderStatic}, nil
+}
+
+func (p *Static) Nationalities(ctx context.Context, names []string) (map[string]*enricher.Nationality, error) {
+	return fetchEach(ctx, names, "", func(ctx context.Context, name string, _ string) (*enricher.Nationality, error) {
+		return p.Nationality(ctx, name)
+	})
+}
+
+// withCountry localizes the query to countryId when it is set.
+func withCountry(query url.Values, countryId string) url.Values {
+	if countryId != "" {
+		query.Set("country_id", countryId)
+	}
+	return query
+}
+
+// localized asks for the prediction in countryId first and falls back to the worldwide
+// one when the name is unknown there, since rare names often lack local samples.
+func localized[T any](countryId string, fetch func(countryId string) (*T, error)) (*T, error) {
+	res, err := fetch(countryId)
+	if countryId != "" && errors.Is(err, domainErr.ErrNameUnknown) {
+		return fetch("")
+	}
+	return res, err
+}
+
+// localizedMany is localized for a batch: the names unknown in countryId are asked worldwide.
+func localizedMany[T any](ctx context.Context, names []string, countryId string, fetch func(context.Context, []string, string) (map[string]*T, error)) (map[string]*T, error) {
+	results, err := fetch(ctx, names, countryId)
+	if countryId == "" || err != nil {
+		return results, err
+	}
+	var unknown []string
+	for _, name := range names {
+		if _, ok := results[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) == 0 {
+		return results, nil
+	}
+	global, err := fetch(ctx, unknown, "")
+	for name, res := range global {
+		results[name] = res
+	}
+	return results, err
+}
+
+// batchQuery builds the providers' multi-name query: name[]=a&name[]=b.
+func batchQuery(names []string) url.Values {
+	query := url.Values{}
+	for _, name := range names {
+		query.Add("name[]", name)
+	}
+	return query
+}
+
+// fetchChunks splits names into provider-sized chunks and fetches them concurrently.
+// Results of the chunks that succeeded are returned together with the first error.
+func fetchChunks[T any](ctx context.Context, names []string, fetch func(context.Context, []string) (map[string]*T, error)) (map[string]*T, error) {
+	var (
+		results  = make(map[string]*T, len(names))
+		firstErr error
+		mu       = &sync.Mutex{}
+		w        = &sync.WaitGroup{}
+	)
+	for start := 0; start < len(names); start += batchSize {
+		end := min(start+batchSize, len(names))
+		w.Add(1)
+		go func(chunk []string) {
+			defer w.Done()
+			res, err := fetch(ctx, chunk)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+			for name, value := range res {
+				results[name] = value
+			}
+		}(names[start:end])
+	}
+	w.Wait()
+	return results, firstErr
+}
+
+// fetchEach answers a batch one name at a time for providers without a multi-name mode.
+func fetchEach[T any](ctx context.Context, names []string, countryId string, fetch func(context.Context, string, string) (*T, error)) (map[string]*T, error) {
+	results := make(map[string]*T, len(names))
+	var firstErr error
+	for _, name := range names {
+		res, err := fetch(ctx, name, countryId)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		results[name] = res
+	}
+	return results, firstErr
+}
@@ -0,0 +1,275 @@
+package enricher
+
+import (
+	"context"
+	"fmt"
+	domainErr "github.com/Kosodaka/enricher-service/internal/domain/errors"
+	"github.com/Kosodaka/enricher-service/internal/domain/ports/enricher"
+	"github.com/Kosodaka/enricher-service/internal/domain/ports/repository"
+	"github.com/Kosodaka/enricher-service/pkg/ptr"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// quota counts the requests sent to a provider during the current UTC day, which is
+// when the providers reset their limits, and refuses requests once either our own
+// budget or the provider's limit is spent.
+type quota struct {
+	mu       sync.Mutex
+	provider string
+	budget   int
+	now      func() time.Time
+
+	day       time.Time
+	used      int
+	limit     *int
+	remaining *int
+	resetAt   *time.Time
+	// dirty is set when the counters changed since they were last saved.
+	dirty bool
+}
+
+func newQuota(provider string, budget int) *quota {
+	q := &quota{
+		provider: provider,
+		budget:   budget,
+		now:      time.Now,
+	}
+	q.day = q.today()
+	return q
+}
+
+func (q *quota) today() time.Time {
+	return q.now().UTC().Truncate(24 * time.Hour)
+}
+
+// roll starts counting anew when the day changed.
+func (q *quota) roll() {
+	if today := q.today(); !today.Equal(q.day) {
+		q.day = today
+		q.used = 0
+		q.limit, q.remaining, q.resetAt = nil, nil, nil
+		q.dirty = true
+	}
+}
+
+// reserve counts a request about to be sent, or refuses it when no quota is left.
+func (q *quota) reserve() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.roll()
+
+	now := q.now()
+	if q.budget > 0 && q.used >= q.budget {
+		return q.refuse(q.day.Add(24*time.Hour).Sub(now), fmt.Sprintf("daily budget of %d requests is spent", q.budget))
+	}
+	if q.remaining != nil && *q.remaining <= 0 && q.resetAt != nil && now.Before(*q.resetAt) {
+		return q.refuse(q.resetAt.Sub(now), "daily limit is spent")
+	}
+	q.used++
+	if q.remaining != nil && *q.remaining > 0 {
+		*q.remaining--
+	}
+	q.dirty = true
+	return nil
+}
+
+func (q *quota) refuse(wait time.Duration, reason string) error {
+	return &domainErr.ProviderError{
+		Provider:   q.provider,
+		RetryAfter: wait,
+		Err:        fmt.Errorf("%w: %s", domainErr.ErrProviderRateLimited, reason),
+	}
+}
+
+// observe takes the limits the provider reported in its response headers.
+func (q *quota) observe(header http.Header) {
+	limit, limitErr := strconv.Atoi(header.Get("X-Rate-Limit-Limit"))
+	remaining, remainingErr := strconv.Atoi(header.Get("X-Rate-Limit-Remaining"))
+	reset, resetErr := strconv.Atoi(header.Get("X-Rate-Limit-Reset"))
+	if limitErr != nil && remainingErr != nil && resetErr != nil {
+		return
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.roll()
+	if limitErr == nil {
+		q.limit = &limit
+	}
+	if remainingErr == nil {
+		q.remaining = &remaining
+	}
+	if resetErr == nil {
+		resetAt := q.now().Add(time.Duration(reset) * time.Second)
+		q.resetAt = &resetAt
+	}
+	q.dirty = true
+}
+
+func (q *quota) snapshot() enricher.ProviderQuota {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.roll()
+	return q.snapshotLocked()
+}
+
+func (q *quota) snapshotLocked() enricher.ProviderQuota {
+	usage := enricher.ProviderQuota{
+		Provider: q.provider,
+		Day:      q.day,
+		Used:     q.used,
+		Budget:   q.budget,
+	}
+	if q.limit != nil {
+		usage.Limit = ptr.To(*q.limit)
+	}
+	if q.remaining != nil {
+		usage.Remaining = ptr.To(*q.remaining)
+	}
+	if q.resetAt != nil {
+		usage.ResetAt = ptr.To(*q.resetAt)
+	}
+	return usage
+}
+
+// restore continues counting from the usage saved for today before a restart.
+func (q *quota) restore(usage enricher.ProviderQuota) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.roll()
+	if !usage.Day.UTC().Equal(q.day) {
+		return
+	}
+	q.used = max(q.used, usage.Used)
+	if q.limit == nil {
+		q.limit = usage.Limit
+	}
+	if q.remaining == nil {
+		q.remaining = usage.Remaining
+	}
+	if q.resetAt == nil {
+		q.resetAt = usage.ResetAt
+	}
+}
+
+// take returns the usage when it changed since the last call.
+func (q *quota) take() (enricher.ProviderQuota, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.roll()
+	if !q.dirty {
+		return enricher.ProviderQuota{}, false
+	}
+	q.dirty = false
+	return q.snapshotLocked(), true
+}
+
+// quoter is implemented by providers that count their requests.
+type quoter interface {
+	quota() *quota
+}
+
+// touch marks the counters to be saved again.
+func (q *quota) touch() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.dirty = true
+}
+
+func (c *apiClient) quota() *quota {
+	return c.usage
+}
+
+// quotas returns the request counters of every upstream provider in use.
+func (e Enricher) quotas() []*quota {
+	var quotas []*quota
+	seen := map[*quota]struct{}{}
+	for _, p := range e.providers() {
+		q, ok := p.(quoter)
+		if !ok {
+			continue
+		}
+		if _, ok := seen[q.quota()]; ok {
+			continue
+		}
+		seen[q.quota()] = struct{}{}
+		quotas = append(quotas, q.quota())
+	}
+	return quotas
+}
+
+// ProviderQuotas reports today's request usage of every upstream provider in use.
+func (e Enricher) ProviderQuotas() []enricher.ProviderQuota {
+	quotas := []enricher.ProviderQuota{}
+	for _, q := range e.quotas() {
+		quotas = append(quotas, q.snapshot())
+	}
+	return quotas
+}
+
+// LoadUsage continues today's request counters from the store.
+func (e Enricher) LoadUsage(ctx context.Context, store repository.ProviderUsageRepository) error {
+	quotas := e.quotas()
+	if len(quotas) == 0 {
+		return nil
+	}
+	usage, err := store.GetProviderUsage(ctx, quotas[0].today())
+	if err != nil {
+		return err
+	}
+	for _, u := range usage {
+		for _, q := range quotas {
+			if q.provider == u.Provider {
+				q.restore(u)
+			}
+		}
+	}
+	return nil
+}
+
+// SaveUsage stores the request counters that changed since they were last saved.
+func (e Enricher) SaveUsage(ctx context.Context, store repository.ProviderUsageRepository) error {
+	var (
+		usage []enricher.ProviderQuota
+		taken []*quota
+	)
+	for _, q := range e.quotas() {
+		if u, ok := q.take(); ok {
+			usage = append(usage, u)
+			taken = append(taken, q)
+		}
+	}
+	if len(usage) == 0 {
+		return nil
+	}
+	if err := store.SaveProviderUsage(ctx, usage); err != nil {
+		for _, q := range taken {
+			q.touch()
+		}
+		return err
+	}
+	return nil
+}
+
+// KeepUsage saves the request counters every interval until ctx is done, and once more then.
+func (e Enricher) KeepUsage(ctx context.Context, store repository.ProviderUsageRepository, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if err := e.SaveUsage(context.WithoutCancel(ctx), store); err != nil {
+				logger.Error("failed to save provider usage", slog.Any("error", err))
+			}
+			return
+		case <-ticker.C:
+			if err := e.SaveUsage(ctx, store); err != nil {
+				logger.Error("failed to save provider usage", slog.Any("error", err))
+			}
+		}
+	}
+}
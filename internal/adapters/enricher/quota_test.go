@@ -0,0 +1,145 @@
+package enricher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	domainErr "github.com/Kosodaka/enricher-service/internal/domain/errors"
+	"github.com/Kosodaka/enricher-service/internal/domain/ports/enricher"
+	mock_repository "github.com/Kosodaka/enricher-service/pkg/mocks/api/repository"
+	"github.com/Kosodaka/enricher-service/pkg/ptr"
+	"github.com/golang/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestQuota_reserve(t *testing.T) {
+	var requests atomic.Int32
+	var remaining atomic.Int32
+	remaining.Store(100)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("X-Rate-Limit-Limit", "100")
+		w.Header().Set("X-Rate-Limit-Remaining", strconv.Itoa(int(remaining.Load())))
+		w.Header().Set("X-Rate-Limit-Reset", "60")
+		json.NewEncoder(w).Encode(PersonAge{Name: "Oleg", Age: ptr.To(60)})
+	}))
+	defer server.Close()
+
+	t.Run("budget", func(t *testing.T) {
+		requests.Store(0)
+		agify := NewAgify(testConfig{url: server.URL, budget: 2})
+		for i := 0; i < 2; i++ {
+			if _, err := agify.Age(context.Background(), "Oleg", ""); err != nil {
+				t.Fatalf("got %v", err)
+			}
+		}
+		_, err := agify.Age(context.Background(), "Oleg", "")
+		if !errors.Is(err, domainErr.ErrProviderRateLimited) || domainErr.RetryAfter(err) <= 0 {
+			t.Fatalf("got %v, want %v with a wait", err, domainErr.ErrProviderRateLimited)
+		}
+		if got := requests.Load(); got != 2 {
+			t.Errorf("got %d requests, want 2", got)
+		}
+		if state := agify.State(); state != StateClosed {
+			t.Errorf("got state %s, want a spent budget not to open the breaker", state)
+		}
+	})
+
+	t.Run("provider limit", func(t *testing.T) {
+		requests.Store(0)
+		remaining.Store(1)
+		agify := NewAgify(testConfig{url: server.URL})
+		if _, err := agify.Age(context.Background(), "Oleg", ""); err != nil {
+			t.Fatalf("got %v", err)
+		}
+		usage := agify.quota().snapshot()
+		if usage.Used != 1 || *usage.Limit != 100 || *usage.Remaining != 1 || usage.ResetAt == nil {
+			t.Errorf("got %+v", usage)
+		}
+
+		remaining.Store(0)
+		if _, err := agify.Age(context.Background(), "Oleg", ""); err != nil {
+			t.Fatalf("got %v", err)
+		}
+		_, err := agify.Age(context.Background(), "Oleg", "")
+		if !errors.Is(err, domainErr.ErrProviderRateLimited) {
+			t.Fatalf("got %v, want %v", err, domainErr.ErrProviderRateLimited)
+		}
+		if wait := domainErr.RetryAfter(err); wait <= 0 || wait > time.Minute {
+			t.Errorf("got wait %s, want the provider reset", wait)
+		}
+		if got := requests.Load(); got != 2 {
+			t.Errorf("got %d requests, want 2", got)
+		}
+	})
+}
+
+func TestQuota_roll(t *testing.T) {
+	now := time.Date(2024, 3, 20, 23, 59, 0, 0, time.UTC)
+	q := newQuota(ProviderAgify, 1)
+	q.now = func() time.Time { return now }
+	q.day = q.today()
+
+	if err := q.reserve(); err != nil {
+		t.Fatalf("got %v", err)
+	}
+	if err := q.reserve(); !errors.Is(err, domainErr.ErrProviderRateLimited) {
+		t.Fatalf("got %v, want %v", err, domainErr.ErrProviderRateLimited)
+	} else if wait := domainErr.RetryAfter(err); wait != time.Minute {
+		t.Errorf("got wait %s, want a minute until midnight", wait)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if err := q.reserve(); err != nil {
+		t.Fatalf("got %v, want the budget renewed the next day", err)
+	}
+}
+
+func TestEnricher_usage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mock_repository.NewMockProviderUsageRepository(ctrl)
+	e, err := NewEnricher(testConfig{url: "http://localhost", budget: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	agify := e.quotas()[0]
+	today := agify.today()
+
+	store.EXPECT().GetProviderUsage(gomock.Any(), today).Return([]enricher.ProviderQuota{
+		{Provider: ProviderAgify, Day: today, Used: 10, Remaining: ptr.To(90)},
+		{Provider: ProviderGenderize, Day: today.Add(-24 * time.Hour), Used: 7},
+	}, nil)
+	if err := e.LoadUsage(context.Background(), store); err != nil {
+		t.Fatal(err)
+	}
+	if err := agify.reserve(); !errors.Is(err, domainErr.ErrProviderRateLimited) {
+		t.Errorf("got %v, want the restored usage to spend the budget", err)
+	}
+
+	var saved []enricher.ProviderQuota
+	store.EXPECT().SaveProviderUsage(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, usage []enricher.ProviderQuota) error {
+		saved = usage
+		return nil
+	}).Times(1)
+	e.quotas()[1].reserve()
+	if err := e.SaveUsage(context.Background(), store); err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != 1 || saved[0].Provider != e.quotas()[1].provider || saved[0].Used != 1 {
+		t.Errorf("got %+v, want only the changed counter", saved)
+	}
+	// Nothing changed since, so nothing is saved.
+	if err := e.SaveUsage(context.Background(), store); err != nil {
+		t.Fatal(err)
+	}
+
+	quotas := e.ProviderQuotas()
+	if len(quotas) != 3 || quotas[0].Used != 10 || quotas[0].Budget != 10 {
+		t.Errorf("got %+v", quotas)
+	}
+}
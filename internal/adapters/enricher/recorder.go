@@ -0,0 +1,60 @@
+package enricher
+
+import (
+	"context"
+	"github.com/Kosodaka/enricher-service/internal/domain/ports/enricher"
+	"net/http"
+	"sync"
+)
+
+// recordedHeaders are the response headers kept with a recorded provider answer.
+var recordedHeaders = []string{
+	"Content-Type",
+	"Date",
+	"Retry-After",
+	"X-Rate-Limit-Limit",
+	"X-Rate-Limit-Remaining",
+	"X-Rate-Limit-Reset",
+}
+
+type recorderKey struct{}
+
+// recorder collects the provider answers of one enrichment across its concurrent requests.
+type recorder struct {
+	mu        sync.Mutex
+	responses []enricher.ProviderResponse
+}
+
+// withRecorder returns a context whose provider answers are collected by the returned recorder.
+func withRecorder(ctx context.Context) (context.Context, *recorder) {
+	r := &recorder{}
+	return context.WithValue(ctx, recorderKey{}, r), r
+}
+
+// record keeps the answer when ctx carries a recorder.
+func record(ctx context.Context, response enricher.ProviderResponse) {
+	r, ok := ctx.Value(recorderKey{}).(*recorder)
+	if !ok {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.responses = append(r.responses, response)
+}
+
+func (r *recorder) list() []enricher.ProviderResponse {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]enricher.ProviderResponse(nil), r.responses...)
+}
+
+// headerSubset returns the recorded headers present in header.
+func headerSubset(header http.Header) map[string]string {
+	subset := map[string]string{}
+	for _, key := range recordedHeaders {
+		if value := header.Get(key); value != "" {
+			subset[key] = value
+		}
+	}
+	return subset
+}
@@ -0,0 +1,171 @@
+package enricher
+
+import (
+	"fmt"
+	"github.com/Kosodaka/enricher-service/internal/domain/ports/enricher"
+)
+
+// Registry holds the providers available for each attribute by name, so the
+// chain serving an attribute can be picked from configuration.
+type Registry struct {
+	ages          map[string]enricher.AgeProvider
+	genders       map[string]enricher.GenderProvider
+	nationalities map[string]enricher.NationalityProvider
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		ages:          map[string]enricher.AgeProvider{},
+		genders:       map[string]enricher.GenderProvider{},
+		nationalities: map[string]enricher.NationalityProvider{},
+	}
+}
+
+// NewDefaultRegistry returns a registry with the built-in providers registered. The dataset
+// is only loaded when a chain uses it, and then it must come from DATASET_PATH.
+func NewDefaultRegistry(cfg Configer) (*Registry, error) {
+	static := NewStatic(cfg)
+	r := NewRegistry()
+	r.RegisterAge(NewAgify(cfg))
+	r.RegisterAge(static)
+	r.RegisterGender(NewGenderize(cfg))
+	r.RegisterGender(static)
+	r.RegisterNationality(NewNationalize(cfg))
+	r.RegisterNationality(static)
+	if !uses(cfg, ProviderDataset) {
+		return r, nil
+	}
+
+	dataset, err := NewDataset(cfg.GetDatasetPath())
+	if err != nil {
+		return nil, err
+	}
+	r.RegisterAge(dataset)
+	r.RegisterGender(dataset)
+	r.RegisterNationality(dataset)
+	return r, nil
+}
+
+// uses reports whether any attribute chain has the provider.
+func uses(cfg Configer, provider string) bool {
+	for _, chain := range [][]string{cfg.GetAgeProviders(), cfg.GetGenderProviders(), cfg.GetNationalityProviders()} {
+		for _, name := range chain {
+			if name == provider {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// WithFallback returns cfg with provider added to every attribute chain that does not
+// use it yet. It goes right before the static provider, which must stay the last one.
+func WithFallback(cfg Configer, provider string) Configer {
+	return fallbackConfig{Configer: cfg, provider: provider}
+}
+
+type fallbackConfig struct {
+	Configer
+	provider string
+}
+
+func (c fallbackConfig) GetAgeProviders() []string {
+	return withFallback(c.Configer.GetAgeProviders(), c.provider)
+}
+
+func (c fallbackConfig) GetGenderProviders() []string {
+	return withFallback(c.Configer.GetGenderProviders(), c.provider)
+}
+
+func (c fallbackConfig) GetNationalityProviders() []string {
+	return withFallback(c.Configer.GetNationalityProviders(), c.provider)
+}
+
+func withFallback(names []string, provider string) []string {
+	chain := make([]string, 0, len(names)+1)
+	added := false
+	for _, name := range names {
+		if name == provider {
+			return names
+		}
+		if name == ProviderStatic && !added {
+			chain = append(chain, provider)
+			added = true
+		}
+		chain = append(chain, name)
+	}
+	if !added {
+		chain = append(chain, provider)
+	}
+	return chain
+}
+
+func (r *Registry) RegisterAge(p enricher.AgeProvider) {
+	r.ages[p.Name()] = p
+}
+
+func (r *Registry) RegisterGender(p enricher.GenderProvider) {
+	r.genders[p.Name()] = p
+}
+
+func (r *Registry) RegisterNationality(p enricher.NationalityProvider) {
+	r.nationalities[p.Name()] = p
+}
+
+func (r *Registry) AgeChain(names []string) (AgeChain, error) {
+	chain := make(AgeChain, 0, len(names))
+	for _, name := range names {
+		p, ok := r.ages[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown age provider %q", name)
+		}
+		chain = append(chain, p)
+	}
+	return chain, nil
+}
+
+func (r *Registry) GenderChain(names []string) (GenderChain, error) {
+	chain := make(GenderChain, 0, len(names))
+	for _, name := range names {
+		p, ok := r.genders[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown gender provider %q", name)
+		}
+		chain = append(chain, p)
+	}
+	return chain, nil
+}
+
+func (r *Registry) NationalityChain(names []string) (NationalityChain, error) {
+	chain := make(NationalityChain, 0, len(names))
+	for _, name := range names {
+		p, ok := r.nationalities[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown nationality provider %q", name)
+		}
+		chain = append(chain, p)
+	}
+	return chain, nil
+}
+
+// Enricher builds an enricher from the chains configured for each attribute.
+func (r *Registry) Enricher(cfg Configer) (*Enricher, error) {
+	age, err := r.AgeChain(cfg.GetAgeProviders())
+	if err != nil {
+		return nil, err
+	}
+	gender, err := r.GenderChain(cfg.GetGenderProviders())
+	if err != nil {
+		return nil, err
+	}
+	nationality, err := r.NationalityChain(cfg.GetNationalityProviders())
+	if err != nil {
+		return nil, err
+	}
+	return &Enricher{
+		Age:         age,
+		Gender:      gender,
+		Nationality: nationality,
+		FailFast:    !cfg.GetPartialEnrichment(),
+	}, nil
+}
@@ -0,0 +1,151 @@
+package rules
+
+import (
+	"github.com/Kosodaka/enricher-service/internal/domain/ports/enricher"
+	"sort"
+	"strings"
+)
+
+// rule maps a name ending to the value it suggests and how sure the suggestion is.
+type rule struct {
+	suffix     string
+	value      string
+	confidence float64
+}
+
+// Patronymics are gendered in both Russian and the Turkic languages of the region.
+var patronymicGenders = []rule{
+	{"ich", "male", 0.99},
+	{"ич", "male", 0.99},
+	{"ogly", "male", 0.95},
+	{"oglu", "male", 0.95},
+	{"оглы", "male", 0.95},
+	{"uly", "male", 0.95},
+	{"улы", "male", 0.95},
+	{"vna", "female", 0.99},
+	{"вна", "female", 0.99},
+	{"ichna", "female", 0.99},
+	{"ична", "female", 0.99},
+	{"kyzy", "female", 0.95},
+	{"qizi", "female", 0.95},
+	{"кызы", "female", 0.95},
+}
+
+// Slavic surnames agree with the gender, foreign ones with the same endings lower the confidence.
+// The -in, -ina, -ova, -eva and -aya endings are only taken in Cyrillic: in Latin script they
+// end too many Western names, such as Martin, Christina, Casanova, Villanueva or Amaya.
+var surnameGenders = []rule{
+	{"skaya", "female", 0.95},
+	{"ska", "female", 0.9},
+	{"ова", "female", 0.95},
+	{"ева", "female", 0.95},
+	{"ёва", "female", 0.95},
+	{"ина", "female", 0.85},
+	{"ая", "female", 0.9},
+	{"ская", "female", 0.95},
+	{"ov", "male", 0.9},
+	{"ev", "male", 0.9},
+	{"oy", "male", 0.8},
+	{"sky", "male", 0.9},
+	{"skiy", "male", 0.95},
+	{"skii", "male", 0.95},
+	{"ski", "male", 0.9},
+	{"ов", "male", 0.9},
+	{"ев", "male", 0.9},
+	{"ёв", "male", 0.9},
+	{"ин", "male", 0.8},
+	{"ой", "male", 0.8},
+	{"ский", "male", 0.95},
+	{"цкий", "male", 0.95},
+}
+
+// Latin -yan and -ian end Western names such as Ryan or Christian, so Armenian surnames are
+// only recognized in Cyrillic.
+var surnameNationalities = []rule{
+	{"ov", "RU", 0.6},
+	{"ev", "RU", 0.6},
+	{"sky", "RU", 0.5},
+	{"skiy", "RU", 0.6},
+	{"skaya", "RU", 0.6},
+	{"ов", "RU", 0.6},
+	{"ова", "RU", 0.6},
+	{"ев", "RU", 0.6},
+	{"ева", "RU", 0.6},
+	{"ин", "RU", 0.5},
+	{"ина", "RU", 0.5},
+	{"ский", "RU", 0.6},
+	{"ская", "RU", 0.6},
+	{"ski", "PL", 0.8},
+	{"ska", "PL", 0.8},
+	{"enko", "UA", 0.85},
+	{"енко", "UA", 0.85},
+	{"chuk", "UA", 0.8},
+	{"чук", "UA", 0.8},
+	{"uk", "UA", 0.7},
+	{"yuk", "UA", 0.75},
+	{"ук", "UA", 0.7},
+	{"юк", "UA", 0.75},
+	{"ян", "AM", 0.9},
+	{"shvili", "GE", 0.95},
+	{"швили", "GE", 0.95},
+	{"dze", "GE", 0.9},
+	{"дзе", "GE", 0.9},
+}
+
+func init() {
+	for _, rules := range [][]rule{patronymicGenders, surnameGenders, surnameNationalities} {
+		// The longest matching ending decides, so -skaya is not taken for -aya.
+		sort.SliceStable(rules, func(i, j int) bool {
+			return len(rules[i].suffix) > len(rules[j].suffix)
+		})
+	}
+}
+
+// Rules infers gender from the patronymic and surname endings of Russian-style names
+// and the likely nationality from the surname alone.
+type Rules struct{}
+
+func NewRules() *Rules {
+	return &Rules{}
+}
+
+func (r *Rules) Infer(surname string, patronymic string) enricher.Inference {
+	gender := combine(match(patronymicGenders, patronymic), match(surnameGenders, surname))
+	nationality := match(surnameNationalities, surname)
+	return enricher.Inference{
+		Gender:                gender.value,
+		GenderConfidence:      gender.confidence,
+		Nationality:           nationality.value,
+		NationalityConfidence: nationality.confidence,
+	}
+}
+
+// match returns the rule for the longest ending of word, or an empty one when none matches.
+func match(rules []rule, word string) rule {
+	word = strings.ToLower(strings.TrimSpace(word))
+	if word == "" {
+		return rule{}
+	}
+	for _, r := range rules {
+		if len(word) > len(r.suffix) && strings.HasSuffix(word, r.suffix) {
+			return r
+		}
+	}
+	return rule{}
+}
+
+// combine merges two independent guesses: agreeing ones strengthen each other and
+// disagreeing ones leave the stronger guess weakened by the other.
+func combine(a rule, b rule) rule {
+	switch {
+	case a.value == "":
+		return b
+	case b.value == "":
+		return a
+	case a.value == b.value:
+		return rule{value: a.value, confidence: 1 - (1-a.confidence)*(1-b.confidence)}
+	case a.confidence < b.confidence:
+		a, b = b, a
+	}
+	return rule{value: a.value, confidence: a.confidence - b.confidence}
+}
@@ -0,0 +1,99 @@
+package rules
+
+import (
+	"github.com/Kosodaka/enricher-service/internal/domain/ports/enricher"
+	"math"
+	"testing"
+)
+
+func TestRules_Infer(t *testing.T) {
+	cases := []struct {
+		name       string
+		surname    string
+		patronymic string
+		want       enricher.Inference
+	}{
+		{
+			name:       "patronymic and surname agree",
+			surname:    "Ivanov",
+			patronymic: "Sergeevich",
+			want:       enricher.Inference{Gender: "male", GenderConfidence: 0.999, Nationality: "RU", NationalityConfidence: 0.6},
+		}, {
+			name:       "longest ending decides",
+			surname:    "Dostoevskaya",
+			patronymic: "Mikhailovna",
+			want:       enricher.Inference{Gender: "female", GenderConfidence: 0.9995, Nationality: "RU", NationalityConfidence: 0.6},
+		}, {
+			name:       "cyrillic",
+			surname:    "Шевченко",
+			patronymic: "Тарасович",
+			want:       enricher.Inference{Gender: "male", GenderConfidence: 0.99, Nationality: "UA", NationalityConfidence: 0.85},
+		}, {
+			name:       "disagreeing rules weaken each other",
+			surname:    "Иванова",
+			patronymic: "Петрович",
+			want:       enricher.Inference{Gender: "male", GenderConfidence: 0.04, Nationality: "RU", NationalityConfidence: 0.6},
+		}, {
+			name:    "genderless surname",
+			surname: "Петросян",
+			want:    enricher.Inference{Nationality: "AM", NationalityConfidence: 0.9},
+		}, {
+			name:    "cyrillic -ina",
+			surname: "Путина",
+			want:    enricher.Inference{Gender: "female", GenderConfidence: 0.85, Nationality: "RU", NationalityConfidence: 0.5},
+		}, {
+			name:    "no rule matches",
+			surname: "Smith",
+		}, {
+			name:    "western -in",
+			surname: "Martin",
+		}, {
+			name:    "western -vin",
+			surname: "Kevin",
+		}, {
+			name:    "western -tin",
+			surname: "Justin",
+		}, {
+			name:    "western -ina",
+			surname: "Regina",
+		}, {
+			name:    "western -tina",
+			surname: "Christina",
+		}, {
+			name:    "western -rina",
+			surname: "Katarina",
+		}, {
+			name:    "western -yan",
+			surname: "Ryan",
+		}, {
+			name:    "western -ryan",
+			surname: "Bryan",
+		}, {
+			name:    "double-barrelled western -yan",
+			surname: "Morgan-Ryan",
+		}, {
+			name:    "western -ova",
+			surname: "Casanova",
+		}, {
+			name:    "western -nova",
+			surname: "Villanova",
+		}, {
+			name:    "western -eva",
+			surname: "Villanueva",
+		}, {
+			name:    "western -aya",
+			surname: "Amaya",
+		},
+	}
+	r := NewRules()
+	for _, testCases := range cases {
+		t.Run(testCases.name, func(t *testing.T) {
+			got := r.Infer(testCases.surname, testCases.patronymic)
+			if got.Gender != testCases.want.Gender || got.Nationality != testCases.want.Nationality ||
+				math.Abs(got.GenderConfidence-testCases.want.GenderConfidence) > 1e-9 ||
+				math.Abs(got.NationalityConfidence-testCases.want.NationalityConfidence) > 1e-9 {
+				t.Errorf("got %+v, want %+v", got, testCases.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/Kosodaka/enricher-service/internal/domain/ports/enricher"
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+type enrichmentCacheRepository struct {
+	db *sqlx.DB
+}
+
+func NewEnrichmentCachePostgres(db *sqlx.DB) *enrichmentCacheRepository {
+	return &enrichmentCacheRepository{
+		db: db,
+	}
+}
+
+func (r *enrichmentCacheRepository) GetEnrichment(ctx context.Context, name string, notBefore time.Time) (*enricher.EnrichData, error) {
+	stmt := "SELECT data FROM enrichment_cache WHERE name = $1 AND updated_at >= $2"
+	var raw []byte
+	err := r.db.QueryRowxContext(ctx, stmt, name, notBefore).Scan(&raw)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data := &enricher.EnrichData{}
+	if err := json.Unmarshal(raw, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (r *enrichmentCacheRepository) SaveEnrichment(ctx context.Context, name string, data *enricher.EnrichData) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	stmt := `INSERT INTO enrichment_cache (name, data, updated_at) VALUES ($1, $2, now())
+			ON CONFLICT (name) DO UPDATE SET data = EXCLUDED.data, updated_at = EXCLUDED.updated_at`
+	_, err = r.db.ExecContext(ctx, stmt, name, raw)
+	return err
+}
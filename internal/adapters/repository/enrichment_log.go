@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Kosodaka/enricher-service/internal/domain/model"
+	"github.com/Kosodaka/enricher-service/internal/domain/ports/enricher"
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+type enrichmentLogRepository struct {
+	db *sqlx.DB
+}
+
+func NewEnrichmentLogPostgres(db *sqlx.DB) *enrichmentLogRepository {
+	return &enrichmentLogRepository{
+		db: db,
+	}
+}
+
+// enrichmentLogRow is an enrichment_log row with its headers still encoded.
+type enrichmentLogRow struct {
+	model.EnrichmentLog
+	Headers []byte `db:"headers"`
+}
+
+func (r *enrichmentLogRepository) AddEnrichmentLog(ctx context.Context, personId int, responses []enricher.ProviderResponse) error {
+	if len(responses) == 0 {
+		return nil
+	}
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt := `INSERT INTO enrichment_log (person_id, provider, url, status, headers, body, latency_ms, created_at, cached)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+	for _, resp := range responses {
+		headers, err := json.Marshal(resp.Headers)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, stmt, personId, resp.Provider, resp.URL, resp.Status, headers, resp.Body,
+			resp.Latency.Milliseconds(), resp.ReceivedAt, resp.Cached)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *enrichmentLogRepository) GetEnrichmentLog(ctx context.Context, personId int) ([]model.EnrichmentLog, error) {
+	stmt := `SELECT id, person_id, provider, url, status, headers, body, latency_ms, created_at, cached
+			FROM enrichment_log WHERE person_id = $1 ORDER BY id`
+	rows := []enrichmentLogRow{}
+	if err := r.db.SelectContext(ctx, &rows, stmt, personId); err != nil {
+		return nil, err
+	}
+
+	logs := make([]model.EnrichmentLog, 0, len(rows))
+	for _, row := range rows {
+		if err := json.Unmarshal(row.Headers, &row.EnrichmentLog.Headers); err != nil {
+			return nil, err
+		}
+		logs = append(logs, row.EnrichmentLog)
+	}
+	return logs, nil
+}
+
+func (r *enrichmentLogRepository) PruneEnrichmentLog(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM enrichment_log WHERE created_at < $1", before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Kosodaka/enricher-service/internal/domain/dto"
+	"github.com/Kosodaka/enricher-service/internal/domain/model"
+	"github.com/lib/pq"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidCursor means the cursor was not issued for the requested sort order.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// sortColumns maps the sortable fields to their column and their value on a person.
+var sortColumns = map[string]func(p *model.Person) interface{}{
+	"id":          func(p *model.Person) interface{} { return p.Id },
+	"name":        func(p *model.Person) interface{} { return p.Name },
+	"surname":     func(p *model.Person) interface{} { return p.Surname },
+	"patronymic":  func(p *model.Person) interface{} { return p.Patronymic },
+	"age":         func(p *model.Person) interface{} { return p.Age },
+	"gender":      func(p *model.Person) interface{} { return p.Gender },
+	"nationality": func(p *model.Person) interface{} { return p.Nationality },
+}
+
+// personQuery collects the conditions of a person query and their positional arguments.
+type personQuery struct {
+	conditions []string
+	args       []interface{}
+}
+
+// arg adds an argument and returns its placeholder.
+func (q *personQuery) arg(value interface{}) string {
+	q.args = append(q.args, value)
+	return "$" + strconv.Itoa(len(q.args))
+}
+
+func (q *personQuery) where(condition string) {
+	q.conditions = append(q.conditions, condition)
+}
+
+// condition joins the conditions, a query without any matches every person.
+func (q *personQuery) condition() string {
+	if len(q.conditions) == 0 {
+		return "TRUE"
+	}
+	return strings.Join(q.conditions, " AND ")
+}
+
+// filter applies every supplied field of the filter, deleted persons match only when included.
+func (q *personQuery) filter(f *dto.PersonFilter) {
+	if !f.IncludeDeleted {
+		q.where("deleted_at IS NULL")
+	}
+	if f.Name != "" {
+		q.where("name = " + q.arg(f.Name))
+	}
+	if f.Surname != "" {
+		q.where("surname = " + q.arg(f.Surname))
+	}
+	if f.Patronymic != "" {
+		q.where("patronymic = " + q.arg(f.Patronymic))
+	}
+	if f.Age != nil {
+		q.where("age = " + q.arg(*f.Age))
+	}
+	if f.AgeGte != nil {
+		q.where("age >= " + q.arg(*f.AgeGte))
+	}
+	if f.AgeLte != nil {
+		q.where("age <= " + q.arg(*f.AgeLte))
+	}
+	if f.Gender != nil {
+		q.where("gender = " + q.arg(*f.Gender))
+	}
+	if len(f.Nationalities) > 0 {
+		q.nationality(f)
+	}
+}
+
+// nationality matches the requested nationalities either as the top one or, in any mode,
+// as any ranked nationality above the minimal probability.
+func (q *personQuery) nationality(f *dto.PersonFilter) {
+	countries := q.arg(pq.Array(f.Nationalities))
+	if f.NationalityMode != dto.NationalityModeAny {
+		q.where("nationality = ANY(" + countries + ")")
+		return
+	}
+	q.where(`EXISTS (SELECT 1 FROM person_nationality pn WHERE pn.person_id = person.id
+			AND pn.country_id = ANY(` + countries + `) AND pn.probability >= ` + q.arg(f.NationalityMinProbability) + `)`)
+}
+
+// order returns the ORDER BY list of the sort, id breaks the ties so the order is total.
+func order(sort []dto.SortField) string {
+	terms := make([]string, 0, len(sort)+1)
+	for _, s := range sort {
+		if s.Desc {
+			terms = append(terms, s.Field+" DESC NULLS LAST")
+		} else {
+			terms = append(terms, s.Field+" ASC NULLS LAST")
+		}
+	}
+	return strings.Join(append(terms, "id ASC"), ", ")
+}
+
+// cursor is the position after the last person of a page: its sort values followed by its id.
+type cursor struct {
+	Sort   string        `json:"s"`
+	Values []interface{} `json:"v"`
+}
+
+// sortKey identifies the sort order a cursor was issued for.
+func sortKey(sort []dto.SortField) string {
+	fields := make([]string, 0, len(sort))
+	for _, s := range sort {
+		if s.Desc {
+			fields = append(fields, "-"+s.Field)
+		} else {
+			fields = append(fields, s.Field)
+		}
+	}
+	return strings.Join(fields, ",")
+}
+
+func encodeCursor(sort []dto.SortField, last *model.Person) string {
+	c := cursor{Sort: sortKey(sort)}
+	for _, s := range sort {
+		c.Values = append(c.Values, sortColumns[s.Field](last))
+	}
+	c.Values = append(c.Values, last.Id)
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(sort []dto.SortField, encoded string) ([]interface{}, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	// Numbers are passed on as text, postgres casts them to the column type.
+	decoder.UseNumber()
+	c := cursor{}
+	if err := decoder.Decode(&c); err != nil || c.Sort != sortKey(sort) || len(c.Values) != len(sort)+1 {
+		return nil, ErrInvalidCursor
+	}
+	for i, value := range c.Values {
+		if number, ok := value.(json.Number); ok {
+			c.Values[i] = number.String()
+		}
+	}
+	return c.Values, nil
+}
+
+// after matches the persons following the cursor position in the sort order. Missing
+// values come last, so nothing but equal ones can follow a missing value. The greater
+// and the missing values of a field are separate alternatives so each can use an index.
+func (q *personQuery) after(sort []dto.SortField, values []interface{}) {
+	sort = append(sort, dto.SortField{Field: "id"})
+	alternatives := make([]string, 0, 2*len(sort))
+	for i, s := range sort {
+		if values[i] == nil {
+			continue
+		}
+		equal := make([]string, 0, i)
+		for j := 0; j < i; j++ {
+			if values[j] == nil {
+				equal = append(equal, sort[j].Field+" IS NULL")
+			} else {
+				equal = append(equal, sort[j].Field+" = "+q.arg(values[j]))
+			}
+		}
+		op := ">"
+		if s.Desc {
+			op = "<"
+		}
+		alternatives = append(alternatives, "("+strings.Join(append(equal, fmt.Sprintf("%s %s %s", s.Field, op, q.arg(values[i]))), " AND ")+")")
+		// Ids are never missing.
+		if s.Field != "id" {
+			alternatives = append(alternatives, "("+strings.Join(append(equal, s.Field+" IS NULL"), " AND ")+")")
+		}
+	}
+	if len(alternatives) == 0 {
+		q.where("FALSE")
+		return
+	}
+	q.where("(" + strings.Join(alternatives, " OR ") + ")")
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"errors"
+	"github.com/Kosodaka/enricher-service/internal/domain/dto"
+	"github.com/Kosodaka/enricher-service/internal/domain/model"
+	"github.com/Kosodaka/enricher-service/pkg/ptr"
+	"reflect"
+	"testing"
+)
+
+func TestPersonQuery_Filter(t *testing.T) {
+	cases := []struct {
+		name      string
+		filter    *dto.PersonFilter
+		condition string
+		args      int
+	}{
+		{
+			name:      "empty filter",
+			filter:    &dto.PersonFilter{IncludeDeleted: true},
+			condition: "TRUE",
+		}, {
+			name:      "age range and nationalities",
+			filter:    &dto.PersonFilter{AgeGte: ptr.To(20), AgeLte: ptr.To(40), Nationalities: []string{"RU", "UA"}},
+			condition: "deleted_at IS NULL AND age >= $1 AND age <= $2 AND nationality = ANY($3)",
+			args:      3,
+		}, {
+			name:      "surname and gender",
+			filter:    &dto.PersonFilter{Person: model.Person{Surname: "Ivanova", Gender: ptr.To("female")}},
+			condition: "deleted_at IS NULL AND surname = $1 AND gender = $2",
+			args:      2,
+		},
+	}
+	for _, testCases := range cases {
+		t.Run(testCases.name, func(t *testing.T) {
+			q := &personQuery{}
+			q.filter(testCases.filter)
+			if got := q.condition(); got != testCases.condition {
+				t.Errorf("got condition %q, want %q", got, testCases.condition)
+			}
+			if len(q.args) != testCases.args {
+				t.Errorf("got %d args, want %d", len(q.args), testCases.args)
+			}
+		})
+	}
+}
+
+func TestCursor(t *testing.T) {
+	sort := []dto.SortField{{Field: "surname"}, {Field: "age", Desc: true}}
+	encoded := encodeCursor(sort, &model.Person{Id: 7, Surname: "Ivanova"})
+
+	values, err := decodeCursor(sort, encoded)
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+	if want := []interface{}{"Ivanova", nil, "7"}; !reflect.DeepEqual(values, want) {
+		t.Errorf("got values %v, want %v", values, want)
+	}
+
+	if _, err := decodeCursor(sort[:1], encoded); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("got error %v for another sort, want %v", err, ErrInvalidCursor)
+	}
+
+	// Only persons of the same surname and no age can follow a person without an age.
+	q := &personQuery{}
+	q.after(sort, values)
+	want := "((surname > $1) OR (surname IS NULL) OR (surname = $2 AND age IS NULL AND id > $3))"
+	if got := q.condition(); got != want {
+		t.Errorf("got condition %q, want %q", got, want)
+	}
+	if want := []interface{}{"Ivanova", "Ivanova", "7"}; !reflect.DeepEqual(q.args, want) {
+		t.Errorf("got args %v, want %v", q.args, want)
+	}
+}
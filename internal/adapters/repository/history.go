@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	domainErr "github.com/Kosodaka/enricher-service/internal/domain/errors"
+	"github.com/Kosodaka/enricher-service/internal/domain/model"
+	"github.com/Kosodaka/enricher-service/pkg/audit"
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+// personChange is a change of a person recorded in its history within the transaction
+// making the change.
+type personChange struct {
+	personId  int64
+	operation string
+	before    []byte
+}
+
+// beginChange locks the person and keeps its state before the change.
+func beginChange(ctx context.Context, tx *sqlx.Tx, personId int64, operation string) (*personChange, error) {
+	before, err := personSnapshot(ctx, tx, personId)
+	if err != nil {
+		return nil, err
+	}
+	return &personChange{personId: personId, operation: operation, before: before}, nil
+}
+
+// record appends the change to the history of the person unless it left the person as it was.
+func (c *personChange) record(ctx context.Context, tx *sqlx.Tx) error {
+	after, err := personSnapshot(ctx, tx, c.personId)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(c.before, after) {
+		return nil
+	}
+	actor, requestId := audit.FromContext(ctx)
+	stmt := `INSERT INTO person_history (person_id, operation, before, after, actor, request_id)
+			VALUES ($1, $2, $3, $4, $5, $6)`
+	_, err = tx.ExecContext(ctx, stmt, c.personId, c.operation, nullJSON(c.before), nullJSON(after), actor, requestId)
+	return err
+}
+
+// personSnapshot returns the person with its ranked nationalities as the API returns it,
+// nil when there is no such person.
+func personSnapshot(ctx context.Context, tx *sqlx.Tx, personId int64) ([]byte, error) {
+	person := &model.Person{}
+	err := tx.GetContext(ctx, person, "SELECT "+personColumns+" FROM person WHERE id = $1 FOR UPDATE", personId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	stmt := "SELECT country_id, probability, rank FROM person_nationality WHERE person_id = $1 ORDER BY rank"
+	if err := tx.SelectContext(ctx, &person.Nationalities, stmt, personId); err != nil {
+		return nil, err
+	}
+	return json.Marshal(person)
+}
+
+// nullJSON passes a missing state as NULL rather than as an empty document.
+func nullJSON(state []byte) interface{} {
+	if state == nil {
+		return nil
+	}
+	return state
+}
+
+// personHistoryRow is a person_history row with its states still encoded.
+type personHistoryRow struct {
+	model.PersonHistory
+	Before []byte `db:"before"`
+	After  []byte `db:"after"`
+}
+
+// GetPersonHistory returns the changes of the person, the oldest first.
+func (r *personRepository) GetPersonHistory(ctx context.Context, personId int) ([]model.PersonHistory, error) {
+	stmt := `SELECT id, person_id, operation, before, after, actor, request_id, created_at
+			FROM person_history WHERE person_id = $1 ORDER BY id`
+	rows := []personHistoryRow{}
+	if err := r.db.SelectContext(ctx, &rows, stmt, personId); err != nil {
+		return nil, err
+	}
+
+	history := make([]model.PersonHistory, 0, len(rows))
+	for _, row := range rows {
+		row.PersonHistory.Before = row.Before
+		row.PersonHistory.After = row.After
+		history = append(history, row.PersonHistory)
+	}
+	return history, nil
+}
+
+// GetPersonAsOf reconstructs the person as it was at the given time from its history.
+func (r *personRepository) GetPersonAsOf(ctx context.Context, personId int, asOf time.Time) (*model.Person, error) {
+	stmt := `SELECT after FROM person_history WHERE person_id = $1 AND created_at <= $2
+			ORDER BY created_at DESC, id DESC LIMIT 1`
+	var after []byte
+	err := r.db.GetContext(ctx, &after, stmt, personId, asOf)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && after == nil) {
+		return nil, domainErr.ErrPersonNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	person := &model.Person{}
+	if err := json.Unmarshal(after, person); err != nil {
+		return nil, err
+	}
+	return person, nil
+}
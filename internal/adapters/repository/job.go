@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/Kosodaka/enricher-service/internal/domain/dto"
+	"github.com/Kosodaka/enricher-service/internal/domain/model"
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+const jobColumns = "id, person_id, country_id, run_id, status, attempts, COALESCE(error, '') AS error, created_at, updated_at"
+
+type jobRepository struct {
+	db    *sqlx.DB
+	lease time.Duration
+}
+
+// NewJobPostgres returns a job queue whose running jobs are handed out again once
+// they have not been finished within lease, e.g. because their worker died.
+func NewJobPostgres(db *sqlx.DB, lease time.Duration) *jobRepository {
+	return &jobRepository{
+		db:    db,
+		lease: lease,
+	}
+}
+
+func (r *jobRepository) AddPersonJob(ctx context.Context, person *model.Person, countryId string) (int, int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	personId, err := insertPerson(ctx, tx, person)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var jobId int
+	stmt := "INSERT INTO enrichment_job (person_id, country_id, status) VALUES ($1, $2, $3) RETURNING id"
+	if err := tx.QueryRowxContext(ctx, stmt, personId, countryId, model.JobStatusQueued).Scan(&jobId); err != nil {
+		return 0, 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, err
+	}
+	return personId, jobId, nil
+}
+
+func (r *jobRepository) GetJob(ctx context.Context, id int) (*model.Job, error) {
+	stmt := "SELECT " + jobColumns + " FROM enrichment_job WHERE id = $1"
+	job := &model.Job{}
+	if err := r.db.QueryRowxContext(ctx, stmt, id).StructScan(job); err != nil {
+		return nil, fmt.Errorf("%s: no such job", err)
+	}
+	return job, nil
+}
+
+func (r *jobRepository) ClaimJob(ctx context.Context) (*model.Job, error) {
+	stmt := `UPDATE enrichment_job SET status = $1, attempts = attempts + 1, updated_at = now()
+			WHERE id = (
+				SELECT id FROM enrichment_job
+				WHERE (status = $2 AND available_at <= now()) OR (status = $1 AND updated_at < $3)
+				ORDER BY id
+				FOR UPDATE SKIP LOCKED
+				LIMIT 1
+			)
+			RETURNING ` + jobColumns
+	job := &model.Job{}
+	err := r.db.QueryRowxContext(ctx, stmt, model.JobStatusRunning, model.JobStatusQueued, time.Now().Add(-r.lease)).StructScan(job)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func (r *jobRepository) CompleteJob(ctx context.Context, job *model.Job, person *model.Person) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := saveEnrichment(ctx, tx, person); err != nil {
+		return err
+	}
+
+	stmt := "UPDATE enrichment_job SET status = $1, error = NULL, updated_at = now() WHERE id = $2"
+	if _, err := tx.ExecContext(ctx, stmt, model.JobStatusDone, job.Id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *jobRepository) FailJob(ctx context.Context, job *model.Job, reason string, retry bool) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	status := model.JobStatusQueued
+	if !retry {
+		status = model.JobStatusFailed
+		change, err := beginChange(ctx, tx, job.PersonId, model.HistoryUpdate)
+		if err != nil {
+			return err
+		}
+		// A person that failed to be re-enriched keeps what was predicted before.
+		stmt := "UPDATE person SET version = version + 1, status = $1 WHERE id = $2 AND status = $3"
+		if _, err := tx.ExecContext(ctx, stmt, model.PersonStatusFailed, job.PersonId, model.PersonStatusPending); err != nil {
+			return err
+		}
+		if err := change.record(ctx, tx); err != nil {
+			return err
+		}
+	}
+
+	stmt := "UPDATE enrichment_job SET status = $1, error = $2, updated_at = now() WHERE id = $3"
+	if _, err := tx.ExecContext(ctx, stmt, status, reason, job.Id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *jobRepository) DeferJob(ctx context.Context, job *model.Job, until time.Time, reason string) error {
+	stmt := `UPDATE enrichment_job SET status = $1, attempts = GREATEST(attempts - 1, 0), error = $2,
+			available_at = $3, updated_at = now() WHERE id = $4`
+	_, err := r.db.ExecContext(ctx, stmt, model.JobStatusQueued, reason, until, job.Id)
+	return err
+}
+
+func (r *jobRepository) AddReenrichRun(ctx context.Context, filter *dto.ReenrichFilter) (*model.ReenrichRun, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var runId int64
+	stmt := "INSERT INTO reenrich_run (status) VALUES ($1) RETURNING id"
+	if err := tx.QueryRowxContext(ctx, stmt, model.RunStatusRunning).Scan(&runId); err != nil {
+		return nil, err
+	}
+
+	q := &personQuery{}
+	q.filter(&filter.PersonFilter)
+	if filter.EnrichedBefore != nil {
+		q.where("(enriched_at IS NULL OR enriched_at < " + q.arg(*filter.EnrichedBefore) + ")")
+	}
+	// Persons are re-enriched localized as they were, the service falls back to its default country.
+	stmt = "INSERT INTO enrichment_job (person_id, country_id, status, run_id) SELECT id, age_country_id, " +
+		q.arg(model.JobStatusQueued) + ", " + q.arg(runId) + " FROM person WHERE " + q.condition() + " ORDER BY id"
+	result, err := tx.ExecContext(ctx, stmt, q.args...)
+	if err != nil {
+		return nil, err
+	}
+	total, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE reenrich_run SET total = $1 WHERE id = $2", total, runId); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetReenrichRun(ctx, int(runId))
+}
+
+func (r *jobRepository) GetReenrichRun(ctx context.Context, id int) (*model.ReenrichRun, error) {
+	// A running run is done once none of its jobs is left to process.
+	stmt := `SELECT r.id, r.total, r.created_at, r.updated_at,
+				CASE WHEN r.status = $2 AND count(j.id) FILTER (WHERE j.status IN ($3, $4)) = 0 THEN $5 ELSE r.status END AS status,
+				count(j.id) FILTER (WHERE j.status = $3) AS queued,
+				count(j.id) FILTER (WHERE j.status = $4) AS running,
+				count(j.id) FILTER (WHERE j.status = $5) AS done,
+				count(j.id) FILTER (WHERE j.status = $6) AS failed,
+				count(j.id) FILTER (WHERE j.status = $7) AS cancelled
+			FROM reenrich_run r LEFT JOIN enrichment_job j ON j.run_id = r.id
+			WHERE r.id = $1
+			GROUP BY r.id`
+	run := &model.ReenrichRun{}
+	err := r.db.QueryRowxContext(ctx, stmt, id, model.RunStatusRunning, model.JobStatusQueued, model.JobStatusRunning,
+		model.JobStatusDone, model.JobStatusFailed, model.JobStatusCancelled).StructScan(run)
+	if err != nil {
+		return nil, fmt.Errorf("%s: no such run", err)
+	}
+	return run, nil
+}
+
+func (r *jobRepository) CancelReenrichRun(ctx context.Context, id int) (*model.ReenrichRun, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	stmt := "UPDATE reenrich_run SET status = $1, updated_at = now() WHERE id = $2 AND status = $3"
+	if _, err := tx.ExecContext(ctx, stmt, model.RunStatusCancelled, id, model.RunStatusRunning); err != nil {
+		return nil, err
+	}
+	// Running jobs are left to finish, the queued ones are never claimed.
+	stmt = "UPDATE enrichment_job SET status = $1, updated_at = now() WHERE run_id = $2 AND status = $3"
+	if _, err := tx.ExecContext(ctx, stmt, model.JobStatusCancelled, id, model.JobStatusQueued); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetReenrichRun(ctx, id)
+}
@@ -2,11 +2,22 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"github.com/Kosodaka/enricher-service/internal/domain/dto"
+	domainErr "github.com/Kosodaka/enricher-service/internal/domain/errors"
 	"github.com/Kosodaka/enricher-service/internal/domain/model"
+	"github.com/Kosodaka/enricher-service/internal/domain/ports/repository"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"time"
 )
 
+const personColumns = `id, name, surname, patronymic, name_latin, surname_latin, patronymic_latin, age, age_count, age_status, age_country_id, gender, gender_probability,
+	gender_count, gender_status, gender_country_id, nationality, nationality_probability, nationality_count, nationality_status, status, enriched_at,
+	review_reason, age_verified, gender_verified, nationality_verified, age_source, gender_source, nationality_source, version, deleted_at`
+
 type personRepository struct {
 	db *sqlx.DB
 }
@@ -23,8 +34,27 @@ func (r *personRepository) AddPerson(ctx context.Context, data *model.Person) (i
 	}
 	defer tx.Rollback()
 
-	stmt := `INSERT INTO person (name, surname, patronymic, age, gender, nationality) 
-			VALUES (:name, :surname, :patronymic, :age, :gender, :nationality) RETURNING id`
+	id, err := insertPerson(ctx, tx, data)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func insertPerson(ctx context.Context, tx *sqlx.Tx, data *model.Person) (int, error) {
+	stmt := `INSERT INTO person (name, surname, patronymic, name_latin, surname_latin, patronymic_latin, age, age_count, age_status, age_country_id, gender, gender_probability,
+			gender_count, gender_status, gender_country_id, nationality, nationality_probability, nationality_count, nationality_status, status,
+			enriched_at, review_reason, age_verified, gender_verified, nationality_verified, age_source, gender_source, nationality_source)
+			VALUES (:name, :surname, :patronymic, :name_latin, :surname_latin, :patronymic_latin, :age, :age_count, :age_status, :age_country_id, :gender, :gender_probability,
+			:gender_count, :gender_status, :gender_country_id, :nationality, :nationality_probability, :nationality_count, :nationality_status, :status,
+			CASE WHEN :status IN ('enriched', 'needs_review') THEN now() END, :review_reason, :age_verified, :gender_verified,
+			:nationality_verified, :age_source, :gender_source, :nationality_source)
+			RETURNING id`
 
 	var id int
 	insertStmt, err := tx.PrepareNamedContext(ctx, stmt)
@@ -37,99 +67,375 @@ func (r *personRepository) AddPerson(ctx context.Context, data *model.Person) (i
 		return 0, err
 	}
 
-	if err := tx.Commit(); err != nil {
+	if err := saveNationalities(ctx, tx, int64(id), data.Nationalities); err != nil {
+		return 0, err
+	}
+	change := &personChange{personId: int64(id), operation: model.HistoryInsert}
+	if err := change.record(ctx, tx); err != nil {
 		return 0, err
 	}
-
 	return id, nil
 }
 
-func (r *personRepository) GetPerson(ctx context.Context, id int) (*model.Person, error) {
-	stmt := "SELECT id, name, surname, patronymic, age, gender, nationality FROM person WHERE id = $1"
+// saveEnrichment overwrites the enriched attributes and the status of an existing person
+// if it is still at the version they were predicted for. Otherwise a change made while the
+// providers were asked, such as an attribute set by hand, would be lost.
+func saveEnrichment(ctx context.Context, tx *sqlx.Tx, data *model.Person) error {
+	change, err := beginChange(ctx, tx, data.Id, model.HistoryUpdate)
+	if err != nil {
+		return err
+	}
+	stmt := `UPDATE person SET version = version + 1, age = :age, age_count = :age_count, age_status = :age_status, age_country_id = :age_country_id,
+			gender = :gender, gender_probability = :gender_probability, gender_count = :gender_count,
+			gender_status = :gender_status, gender_country_id = :gender_country_id,
+			nationality = :nationality, nationality_probability = :nationality_probability,
+			nationality_count = :nationality_count, nationality_status = :nationality_status, status = :status,
+			review_reason = :review_reason, age_source = :age_source, gender_source = :gender_source,
+			nationality_source = :nationality_source, enriched_at = now()
+			WHERE id = :id AND version = :version AND deleted_at IS NULL
+			RETURNING version`
+	updateStmt, err := tx.PrepareNamedContext(ctx, stmt)
+	if err != nil {
+		return err
+	}
+
+	var version int64
+	err = updateStmt.GetContext(ctx, &version, data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return versionConflict(ctx, tx, data)
+	}
+	if err != nil {
+		return err
+	}
+	data.Version = version
+
+	if err := saveNationalities(ctx, tx, data.Id, data.Nationalities); err != nil {
+		return err
+	}
+	return change.record(ctx, tx)
+}
+
+// UpdateEnrichment saves the attributes of a re-enriched person.
+func (r *personRepository) UpdateEnrichment(ctx context.Context, data *model.Person) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := saveEnrichment(ctx, tx, data); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetPersonsToReview returns the persons under review, the oldest first.
+func (r *personRepository) GetPersonsToReview(ctx context.Context) ([]model.Person, error) {
+	stmt := "SELECT " + personColumns + " FROM person WHERE status = $1 AND deleted_at IS NULL ORDER BY id"
+	persons := []model.Person{}
+	if err := r.db.SelectContext(ctx, &persons, stmt, model.PersonStatusNeedsReview); err != nil {
+		return nil, err
+	}
+	if err := r.loadNationalities(ctx, persons); err != nil {
+		return nil, err
+	}
+	return persons, nil
+}
+
+// SaveReview saves the attributes of a reviewed person and takes it out of the review queue
+// if it is still at the version the review was based on.
+func (r *personRepository) SaveReview(ctx context.Context, data *model.Person) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	change, err := beginChange(ctx, tx, data.Id, model.HistoryUpdate)
+	if err != nil {
+		return err
+	}
+	stmt := `UPDATE person SET version = version + 1, age = :age, age_count = :age_count, age_status = :age_status, age_country_id = :age_country_id,
+			gender = :gender, gender_probability = :gender_probability, gender_count = :gender_count,
+			gender_status = :gender_status, gender_country_id = :gender_country_id,
+			nationality = :nationality, nationality_probability = :nationality_probability,
+			nationality_count = :nationality_count, nationality_status = :nationality_status, status = :status,
+			review_reason = :review_reason, age_verified = :age_verified, gender_verified = :gender_verified,
+			nationality_verified = :nationality_verified, age_source = :age_source, gender_source = :gender_source,
+			nationality_source = :nationality_source
+			WHERE id = :id AND version = :version AND status = 'needs_review' AND deleted_at IS NULL
+			RETURNING version`
+	updateStmt, err := tx.PrepareNamedContext(ctx, stmt)
+	if err != nil {
+		return err
+	}
+
+	var version int64
+	err = updateStmt.GetContext(ctx, &version, data)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = versionConflict(ctx, tx, data)
+		// A person still at the version is not under review anymore.
+		var conflict *repository.ErrVersionConflict
+		if errors.As(err, &conflict) && conflict.Current == conflict.Expected {
+			return domainErr.ErrNotUnderReview
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	data.Version = version
+
+	if err := saveNationalities(ctx, tx, data.Id, data.Nationalities); err != nil {
+		return err
+	}
+	if err := change.record(ctx, tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// saveNationalities replaces the ranked nationalities of a person.
+func saveNationalities(ctx context.Context, tx *sqlx.Tx, personId int64, nationalities []model.PersonNationality) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM person_nationality WHERE person_id = $1", personId); err != nil {
+		return err
+	}
+	for _, n := range nationalities {
+		_, err := tx.ExecContext(ctx, "INSERT INTO person_nationality (person_id, country_id, probability, rank) VALUES ($1, $2, $3, $4)",
+			personId, n.CountryId, n.Probability, n.Rank)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetPerson returns the person, a deleted one only when includeDeleted is set.
+func (r *personRepository) GetPerson(ctx context.Context, id int, includeDeleted bool) (*model.Person, error) {
+	stmt := "SELECT " + personColumns + " FROM person WHERE id = $1"
+	if !includeDeleted {
+		stmt += " AND deleted_at IS NULL"
+	}
 	person := &model.Person{}
 	err := r.db.QueryRowxContext(ctx, stmt, id).StructScan(person)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domainErr.ErrPersonNotFound
+	}
 	if err != nil {
 		return nil, fmt.Errorf("%s: no such user", err)
 	}
 
+	stmt = "SELECT country_id, probability, rank FROM person_nationality WHERE person_id = $1 ORDER BY rank"
+	if err := r.db.SelectContext(ctx, &person.Nationalities, stmt, id); err != nil {
+		return nil, err
+	}
+
 	return person, nil
 }
 
-func (r *personRepository) GetPersons(ctx context.Context, data *model.Person) ([]model.Person, error) {
-	stmt := `SELECT id, name, surname, patronymic, age, gender, nationality FROM person WHERE name = name AND surname = surname 
-             AND patronymic = patronymic AND age = age AND gender = gender AND nationality = nationality`
+// GetPersons returns a page of the persons matching the filter in its sort order.
+// The page continues after the cursor, if any, and holds one more person than the
+// limit only to tell whether another page follows.
+func (r *personRepository) GetPersons(ctx context.Context, data *dto.PersonFilter) (*dto.PersonPage, error) {
+	q := &personQuery{}
+	q.filter(data)
+	if data.Cursor != "" {
+		values, err := decodeCursor(data.Sort, data.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		q.after(data.Sort, values)
+	}
+	stmt := "SELECT " + personColumns + " FROM person WHERE " + q.condition() +
+		" ORDER BY " + order(data.Sort) + " LIMIT " + q.arg(data.Limit+1)
 	persons := []model.Person{}
-	rows, err := r.db.NamedQueryContext(ctx, stmt, data)
-	if err != nil {
+	if err := r.db.SelectContext(ctx, &persons, stmt, q.args...); err != nil {
 		return nil, err
 	}
-	for rows.Next() {
-		person := &model.Person{}
-		if err := rows.StructScan(person); err != nil {
-			return nil, err
-		}
-		persons = append(persons, *person)
+
+	page := &dto.PersonPage{Persons: persons}
+	if len(persons) > data.Limit {
+		page.Persons = persons[:data.Limit]
+		page.NextCursor = encodeCursor(data.Sort, &page.Persons[data.Limit-1])
+	}
+	if err := r.loadNationalities(ctx, page.Persons); err != nil {
+		return nil, err
 	}
+	return page, nil
+}
 
-	return persons, nil
+// loadNationalities fills the ranked nationalities of persons with a single query.
+func (r *personRepository) loadNationalities(ctx context.Context, persons []model.Person) error {
+	if len(persons) == 0 {
+		return nil
+	}
+	ids := make([]int64, 0, len(persons))
+	byId := make(map[int64]*model.Person, len(persons))
+	for i := range persons {
+		ids = append(ids, persons[i].Id)
+		byId[persons[i].Id] = &persons[i]
+	}
+
+	rows := []struct {
+		PersonId int64 `db:"person_id"`
+		model.PersonNationality
+	}{}
+	stmt := "SELECT person_id, country_id, probability, rank FROM person_nationality WHERE person_id = ANY($1) ORDER BY person_id, rank"
+	if err := r.db.SelectContext(ctx, &rows, stmt, pq.Array(ids)); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		person := byId[row.PersonId]
+		person.Nationalities = append(person.Nationalities, row.PersonNationality)
+	}
+	return nil
 }
 
 func (r *personRepository) UpdatePerson(ctx context.Context, data *model.Person) error {
-	tx, err := r.db.BeginTxx(context.Background(), nil)
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	stmt := "UPDATE person SET name = :name,surname = :surname,patronymic = :patronymic,age = :age,gender = :gender, nationality = :nationality WHERE id = :id"
-	updateStmt, err := tx.PrepareNamedContext(ctx, stmt)
+	change, err := beginChange(ctx, tx, data.Id, model.HistoryUpdate)
 	if err != nil {
 		return err
 	}
-
-	result, err := updateStmt.Exec(data)
+	var nationality sql.NullString
+	err = tx.GetContext(ctx, &nationality, "SELECT nationality FROM person WHERE id = $1", data.Id)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	// Changed attributes were set by hand now and are kept from re-enrichment.
+	stmt := `UPDATE person SET version = version + 1, name = :name,surname = :surname,patronymic = :patronymic,
+			name_latin = :name_latin, surname_latin = :surname_latin, patronymic_latin = :patronymic_latin,age = :age,gender = :gender, nationality = :nationality,
+			age_status = 'ok', gender_status = 'ok', nationality_status = 'ok',
+			age_source = CASE WHEN age IS DISTINCT FROM :age THEN 'manual' ELSE age_source END,
+			gender_source = CASE WHEN gender IS DISTINCT FROM :gender THEN 'manual' ELSE gender_source END,
+			nationality_source = CASE WHEN nationality IS DISTINCT FROM :nationality THEN 'manual' ELSE nationality_source END,
+			age_verified = age_verified OR age IS DISTINCT FROM :age,
+			gender_verified = gender_verified OR gender IS DISTINCT FROM :gender,
+			nationality_verified = nationality_verified OR nationality IS DISTINCT FROM :nationality
+			WHERE id = :id AND (version = :version OR :version = -1) AND deleted_at IS NULL
+			RETURNING version`
+	updateStmt, err := tx.PrepareNamedContext(ctx, stmt)
 	if err != nil {
 		return err
 	}
 
-	rows, err := result.RowsAffected()
+	var version int64
+	err = updateStmt.GetContext(ctx, &version, data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return versionConflict(ctx, tx, data)
+	}
 	if err != nil {
 		return err
 	}
-	if rows == 0 {
-		return fmt.Errorf("%s: no such user", err)
+	data.Version = version
+
+	// A nationality set by hand replaces the predicted distribution, as when it is set on creation.
+	if nationality.Valid != (data.Nationality != nil) || (data.Nationality != nil && nationality.String != *data.Nationality) {
+		nationalities := []model.PersonNationality{}
+		if data.Nationality != nil {
+			nationalities = append(nationalities, model.PersonNationality{CountryId: *data.Nationality, Probability: 1, Rank: 1})
+		}
+		if err := saveNationalities(ctx, tx, data.Id, nationalities); err != nil {
+			return err
+		}
+		data.Nationalities = nationalities
 	}
 
+	if err := change.record(ctx, tx); err != nil {
+		return err
+	}
 	return tx.Commit()
 }
 
+// versionConflict tells why an update matched no person: it is either gone or at another version.
+func versionConflict(ctx context.Context, tx *sqlx.Tx, data *model.Person) error {
+	var current int64
+	err := tx.GetContext(ctx, &current, "SELECT version FROM person WHERE id = $1 AND deleted_at IS NULL", data.Id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domainErr.ErrPersonNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return &repository.ErrVersionConflict{Id: data.Id, Expected: data.Version, Current: current}
+}
+
+// DeletePerson marks the person deleted, it is hidden from reads until restored or purged.
 func (r *personRepository) DeletePerson(ctx context.Context, id int) error {
+	return r.setDeleted(ctx, id, model.HistoryDelete,
+		"UPDATE person SET version = version + 1, deleted_at = now() WHERE id = $1 AND deleted_at IS NULL")
+}
+
+// RestorePerson brings a deleted person back.
+func (r *personRepository) RestorePerson(ctx context.Context, id int) error {
+	return r.setDeleted(ctx, id, model.HistoryUpdate,
+		"UPDATE person SET version = version + 1, deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL")
+}
+
+// setDeleted runs the statement deleting or restoring the person and records the change.
+func (r *personRepository) setDeleted(ctx context.Context, id int, operation, stmt string) error {
 	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	stmt := "DELETE FROM person WHERE id = $1"
-
-	deleteStmt, err := tx.PrepareContext(ctx, stmt)
+	change, err := beginChange(ctx, tx, int64(id), operation)
 	if err != nil {
 		return err
 	}
-
-	result, err := deleteStmt.Exec(id)
+	result, err := tx.ExecContext(ctx, stmt, id)
 	if err != nil {
-		return fmt.Errorf("%s: no such user", err)
+		return err
 	}
-
 	rows, err := result.RowsAffected()
-
 	if err != nil {
 		return err
 	}
 	if rows == 0 {
-		fmt.Errorf("%s: no such user", err)
+		return domainErr.ErrPersonNotFound
+	}
+	if err := change.record(ctx, tx); err != nil {
+		return err
 	}
-
 	return tx.Commit()
 }
+
+// PurgePersons removes the persons deleted before the given time for good and reports how many there were.
+func (r *personRepository) PurgePersons(ctx context.Context, before time.Time) (int64, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	ids := []int64{}
+	if err := tx.SelectContext(ctx, &ids, "SELECT id FROM person WHERE deleted_at < $1 FOR UPDATE", before); err != nil {
+		return 0, err
+	}
+	changes := make([]*personChange, 0, len(ids))
+	for _, id := range ids {
+		change, err := beginChange(ctx, tx, id, model.HistoryDelete)
+		if err != nil {
+			return 0, err
+		}
+		changes = append(changes, change)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM person WHERE id = ANY($1)", pq.Array(ids)); err != nil {
+		return 0, err
+	}
+	for _, change := range changes {
+		if err := change.record(ctx, tx); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return int64(len(ids)), nil
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/Kosodaka/enricher-service/internal/domain/dto"
+	"github.com/Kosodaka/enricher-service/internal/domain/model"
+	"github.com/Kosodaka/enricher-service/pkg/trigram"
+	"github.com/lib/pq"
+	"sort"
+	"strings"
+)
+
+// fullName and fullNameLatin are the expressions the trigram indexes of the person
+// table are built on.
+const (
+	fullName      = "(name || ' ' || surname || ' ' || coalesce(patronymic, ''))"
+	fullNameLatin = "(name_latin || ' ' || surname_latin || ' ' || patronymic_latin)"
+)
+
+// undefinedFunction is the postgres error code of a missing function or operator.
+const undefinedFunction = "42883"
+
+// searchCandidates caps the persons scored in memory when pg_trgm is missing.
+const searchCandidates = 5000
+
+// likeEscaper escapes the wildcards of a LIKE pattern.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// SearchPersons returns the persons whose full name, as written or in Latin letters, is
+// similar to the query, the most similar first. Without pg_trgm the persons are ranked
+// in memory the same way.
+func (r *personRepository) SearchPersons(ctx context.Context, query string, limit int, includeDeleted bool) ([]dto.PersonMatch, error) {
+	stmt := "SELECT " + personColumns + ", GREATEST(word_similarity($1, " + fullName + "), word_similarity($1, " + fullNameLatin +
+		")) AS score FROM person WHERE ($1 <% " + fullName + " OR $1 <% " + fullNameLatin +
+		") AND ($3 OR deleted_at IS NULL) ORDER BY score DESC, id LIMIT $2"
+	matches := []dto.PersonMatch{}
+	err := r.db.SelectContext(ctx, &matches, stmt, query, limit, includeDeleted)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == undefinedFunction {
+		matches, err = r.searchInMemory(ctx, query, limit, includeDeleted)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	persons := make([]model.Person, len(matches))
+	for i := range matches {
+		persons[i] = matches[i].Person
+	}
+	if err := r.loadNationalities(ctx, persons); err != nil {
+		return nil, err
+	}
+	for i := range matches {
+		matches[i].Person = persons[i]
+	}
+	return matches, nil
+}
+
+// searchInMemory scores the persons sharing a piece of a query word with the word
+// similarity of pg_trgm, at most searchCandidates of them.
+func (r *personRepository) searchInMemory(ctx context.Context, query string, limit int, includeDeleted bool) ([]dto.PersonMatch, error) {
+	q := &personQuery{}
+	if !includeDeleted {
+		q.where("deleted_at IS NULL")
+	}
+	q.resembles(query)
+	stmt := "SELECT " + personColumns + " FROM person WHERE " + q.condition() + " ORDER BY id LIMIT " + q.arg(searchCandidates)
+	rows, err := r.db.QueryxContext(ctx, stmt, q.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := []dto.PersonMatch{}
+	for rows.Next() {
+		person := model.Person{}
+		if err := rows.StructScan(&person); err != nil {
+			return nil, err
+		}
+		score := max(trigram.WordSimilarity(query, person.Name+" "+person.Surname+" "+person.Patronymic),
+			trigram.WordSimilarity(query, person.NameLatin+" "+person.SurnameLatin+" "+person.PatronymicLatin))
+		if score >= trigram.Threshold {
+			matches = append(matches, dto.PersonMatch{Person: person, Score: score})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+// resembles keeps the persons whose full name, as written or in Latin letters, contains any
+// three letters of a query word, or a whole word shorter than that, a similar name almost
+// always shares one of them.
+func (q *personQuery) resembles(query string) {
+	pieces := []string{}
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		letters := []rune(word)
+		if len(letters) < 3 {
+			pieces = append(pieces, word)
+			continue
+		}
+		for i := 0; i+3 <= len(letters); i++ {
+			pieces = append(pieces, string(letters[i:i+3]))
+		}
+	}
+	if len(pieces) == 0 {
+		return
+	}
+	for i, piece := range pieces {
+		pieces[i] = "%" + likeEscaper.Replace(piece) + "%"
+	}
+	arg := q.arg(pq.Array(pieces))
+	q.where("(" + fullName + " ILIKE ANY(" + arg + ") OR " + fullNameLatin + " ILIKE ANY(" + arg + "))")
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"github.com/lib/pq"
+	"reflect"
+	"testing"
+)
+
+func TestPersonQuery_Resembles(t *testing.T) {
+	cases := []struct {
+		name      string
+		query     string
+		condition string
+		pieces    []string
+	}{
+		{
+			name:      "blank query",
+			query:     "  ",
+			condition: "TRUE",
+		}, {
+			name:      "pieces of every word",
+			query:     "Ivan Li",
+			condition: "(" + fullName + " ILIKE ANY($1) OR " + fullNameLatin + " ILIKE ANY($1))",
+			pieces:    []string{"%iva%", "%van%", "%li%"},
+		}, {
+			name:      "wildcards are escaped",
+			query:     "a%_",
+			condition: "(" + fullName + " ILIKE ANY($1) OR " + fullNameLatin + " ILIKE ANY($1))",
+			pieces:    []string{`%a\%\_%`},
+		},
+	}
+	for _, testCases := range cases {
+		t.Run(testCases.name, func(t *testing.T) {
+			q := &personQuery{}
+			q.resembles(testCases.query)
+			if got := q.condition(); got != testCases.condition {
+				t.Errorf("got condition %q, want %q", got, testCases.condition)
+			}
+			if testCases.pieces == nil {
+				if len(q.args) != 0 {
+					t.Errorf("got args %v, want none", q.args)
+				}
+				return
+			}
+			if got := *q.args[0].(*pq.StringArray); !reflect.DeepEqual([]string(got), testCases.pieces) {
+				t.Errorf("got pieces %v, want %v", got, testCases.pieces)
+			}
+		})
+	}
+}
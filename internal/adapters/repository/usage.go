@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"github.com/Kosodaka/enricher-service/internal/domain/ports/enricher"
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+type providerUsageRepository struct {
+	db *sqlx.DB
+}
+
+func NewProviderUsagePostgres(db *sqlx.DB) *providerUsageRepository {
+	return &providerUsageRepository{
+		db: db,
+	}
+}
+
+func (r *providerUsageRepository) GetProviderUsage(ctx context.Context, day time.Time) ([]enricher.ProviderQuota, error) {
+	stmt := "SELECT provider, day, used, rate_limit, remaining, reset_at FROM provider_usage WHERE day = $1"
+	usage := []enricher.ProviderQuota{}
+	if err := r.db.SelectContext(ctx, &usage, stmt, day); err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+func (r *providerUsageRepository) SaveProviderUsage(ctx context.Context, usage []enricher.ProviderQuota) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt := `INSERT INTO provider_usage (provider, day, used, rate_limit, remaining, reset_at, updated_at)
+			VALUES (:provider, :day, :used, :rate_limit, :remaining, :reset_at, now())
+			ON CONFLICT (provider, day) DO UPDATE SET used = GREATEST(provider_usage.used, EXCLUDED.used),
+			rate_limit = EXCLUDED.rate_limit, remaining = EXCLUDED.remaining, reset_at = EXCLUDED.reset_at,
+			updated_at = EXCLUDED.updated_at`
+	for _, u := range usage {
+		if _, err := tx.NamedExecContext(ctx, stmt, u); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
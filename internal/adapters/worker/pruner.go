@@ -0,0 +1,48 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// PruneFunc deletes the records older than before and reports how many there were.
+type PruneFunc func(ctx context.Context, before time.Time) (int64, error)
+
+// Pruner periodically deletes the records that outlived their retention period.
+type Pruner struct {
+	name      string
+	prune     PruneFunc
+	retention time.Duration
+	interval  time.Duration
+	logger    *slog.Logger
+}
+
+func NewPruner(name string, prune PruneFunc, retention time.Duration, interval time.Duration, logger *slog.Logger) *Pruner {
+	return &Pruner{
+		name:      name,
+		prune:     prune,
+		retention: retention,
+		interval:  interval,
+		logger:    logger,
+	}
+}
+
+// Run prunes once right away and then every interval until ctx is done.
+func (p *Pruner) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		pruned, err := p.prune(ctx, time.Now().Add(-p.retention))
+		if err != nil && ctx.Err() == nil {
+			p.logger.Error("failed to prune "+p.name, slog.Any("error", err))
+		} else if pruned > 0 {
+			p.logger.Info("pruned "+p.name, slog.Int64("count", pruned))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"github.com/Kosodaka/enricher-service/pkg/logger"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPruner_Run(t *testing.T) {
+	const retention = time.Hour
+	var calls atomic.Int64
+	var badCutoff atomic.Bool
+	prune := func(ctx context.Context, before time.Time) (int64, error) {
+		if age := time.Since(before); age < retention || age > retention+time.Minute {
+			badCutoff.Store(true)
+		}
+		if calls.Add(1)%2 == 0 {
+			return 0, errors.New("db is down")
+		}
+		return 1, nil
+	}
+	pruner := NewPruner("records", prune, retention, time.Millisecond, logger.SetupLogger("test"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		pruner.Run(ctx)
+		close(done)
+	}()
+
+	deadline := time.After(5 * time.Second)
+	for calls.Load() < 5 {
+		select {
+		case <-deadline:
+			t.Fatalf("pruned %d times, want 5", calls.Load())
+		case <-time.After(time.Millisecond):
+		}
+	}
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("pruner did not stop after cancel")
+	}
+	if badCutoff.Load() {
+		t.Fatal("pruned with a cutoff outside the retention period")
+	}
+}
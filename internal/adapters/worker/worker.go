@@ -0,0 +1,72 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+type Configer interface {
+	GetEnrichWorkers() int
+	GetEnrichPollInterval() time.Duration
+}
+
+// Processor handles one queued job and reports false when there was nothing to do.
+type Processor interface {
+	ProcessJob(ctx context.Context) (bool, error)
+}
+
+// Pool runs workers that drain the enrichment job queue.
+type Pool struct {
+	processor Processor
+	workers   int
+	interval  time.Duration
+	logger    *slog.Logger
+}
+
+func NewPool(processor Processor, cfg Configer, logger *slog.Logger) *Pool {
+	workers := cfg.GetEnrichWorkers()
+	if workers < 1 {
+		workers = 1
+	}
+	return &Pool{
+		processor: processor,
+		workers:   workers,
+		interval:  cfg.GetEnrichPollInterval(),
+		logger:    logger,
+	}
+}
+
+// Run blocks until ctx is done and all workers have finished their current job.
+func (p *Pool) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < p.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.work(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+func (p *Pool) work(ctx context.Context) {
+	for {
+		processed, err := p.processor.ProcessJob(ctx)
+		if err != nil {
+			p.logger.Error("failed to process enrichment job", slog.Any("error", err))
+		}
+		if processed && err == nil {
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(p.interval):
+		}
+	}
+}
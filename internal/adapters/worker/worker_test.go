@@ -0,0 +1,62 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"github.com/Kosodaka/enricher-service/pkg/logger"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testConfig struct{}
+
+func (testConfig) GetEnrichWorkers() int                { return 3 }
+func (testConfig) GetEnrichPollInterval() time.Duration { return time.Millisecond }
+
+type queue struct {
+	jobs      atomic.Int64
+	processed atomic.Int64
+	calls     atomic.Int64
+}
+
+func (q *queue) ProcessJob(ctx context.Context) (bool, error) {
+	if q.calls.Add(1)%5 == 0 {
+		return false, errors.New("db is down")
+	}
+	if q.jobs.Add(-1) < 0 {
+		q.jobs.Add(1)
+		return false, nil
+	}
+	q.processed.Add(1)
+	return true, nil
+}
+
+func TestPool_Run(t *testing.T) {
+	q := &queue{}
+	q.jobs.Store(50)
+	pool := NewPool(q, testConfig{}, logger.SetupLogger("test"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		pool.Run(ctx)
+		close(done)
+	}()
+
+	deadline := time.After(5 * time.Second)
+	for q.processed.Load() < 50 {
+		select {
+		case <-deadline:
+			t.Fatalf("processed %d jobs, want 50", q.processed.Load())
+		case <-time.After(time.Millisecond):
+		}
+	}
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("pool did not stop after cancel")
+	}
+}
@@ -1,8 +1,94 @@
 package dto
 
+import (
+	"github.com/Kosodaka/enricher-service/internal/domain/model"
+	"time"
+)
+
+const (
+	// NationalityModeTop matches persons by their most probable nationality.
+	NationalityModeTop = "top"
+	// NationalityModeAny matches persons by any ranked nationality above the minimal probability.
+	NationalityModeAny = "any"
+)
+
 type AddPersonDTO struct {
 	Id         int    `json:"id,string"`
 	Name       string `json:"name" db:"name"`
 	Surname    string `json:"surname" db:"surname"`
 	Patronymic string `json:"patronymic" db:"patronymic"`
+	// CountryId localizes the age and gender predictions, the configured default is used when empty.
+	CountryId string `json:"country_id" db:"-"`
+	// Age, Gender and Nationality are known by the client, the providers are only asked for the missing ones.
+	Age         *int    `json:"age" db:"-"`
+	Gender      *string `json:"gender" db:"-"`
+	Nationality *string `json:"nationality" db:"-"`
+}
+
+// Page sizes of the person list.
+const (
+	DefaultPersonLimit = 50
+	MaxPersonLimit     = 1000
+)
+
+// PersonSortFields are the fields the person list can be sorted by.
+var PersonSortFields = []string{"id", "name", "surname", "patronymic", "age", "gender", "nationality"}
+
+// SortField orders the person list by one field, persons missing the field come last.
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// PersonFilter selects persons by every supplied field. The attributes of the embedded
+// person match exactly, except Nationality which is replaced by Nationalities.
+type PersonFilter struct {
+	model.Person
+	NationalityMode           string  `db:"nationality_mode"`
+	NationalityMinProbability float64 `db:"nationality_min_probability"`
+	// Nationalities matches persons of any of the countries.
+	Nationalities []string `json:"nationality" db:"-"`
+	AgeGte        *int     `json:"age_gte" db:"-"`
+	AgeLte        *int     `json:"age_lte" db:"-"`
+	// Sort orders the page, ties and the default order are by id.
+	Sort []SortField `json:"sort" db:"-"`
+	// Limit is the page size and Cursor the opaque position a previous page ended at.
+	Limit  int    `json:"limit" db:"-"`
+	Cursor string `json:"cursor" db:"-"`
+	// IncludeDeleted also matches the deleted persons not purged yet.
+	IncludeDeleted bool `json:"include_deleted" db:"-"`
+}
+
+// PersonPage is a page of the person list. NextCursor is empty on the last page.
+type PersonPage struct {
+	Persons    []model.Person `json:"persons"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+// Search result sizes.
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+)
+
+// PersonMatch is a person found by its approximate full name. Score is the word
+// similarity of the query to the full name, from 0 to 1.
+type PersonMatch struct {
+	model.Person
+	Score float64 `json:"score" db:"score"`
+}
+
+// ReenrichFilter selects the persons of a bulk re-enrichment. EnrichedBefore also
+// matches the persons the providers never answered for.
+type ReenrichFilter struct {
+	PersonFilter
+	EnrichedBefore *time.Time `db:"enriched_before"`
+}
+
+// ReviewDTO confirms a person under review. The supplied attributes correct the
+// predicted ones and are marked as verified.
+type ReviewDTO struct {
+	Age         *int    `json:"age"`
+	Gender      *string `json:"gender"`
+	Nationality *string `json:"nationality"`
 }
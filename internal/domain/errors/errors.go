@@ -10,4 +10,19 @@ var (
 	EmptyField    = errors.New("cannot be blank")
 	InvalidId     = errors.New("invalid id")
 	NotExistId    = errors.New("sql: no rows in result set: no such user : failed to get person in service")
+
+	// ErrProviderUnavailable means the provider could not be reached, failed or its circuit is open.
+	ErrProviderUnavailable = errors.New("provider is unavailable")
+	// ErrProviderRateLimited means the provider refused the request because of its rate limit.
+	ErrProviderRateLimited = errors.New("provider rate limit exceeded")
+	// ErrProviderBadResponse means the provider answered with an unexpected status or body.
+	ErrProviderBadResponse = errors.New("provider returned a bad response")
+	// ErrNameUnknown means the provider answered but knows nothing about the name.
+	ErrNameUnknown = errors.New("name is unknown to provider")
+
+	// ErrPersonNotFound means there is no such person, or no such deleted one to restore.
+	ErrPersonNotFound = errors.New("no such person")
+
+	// ErrNotUnderReview means the person is not in the review queue.
+	ErrNotUnderReview = errors.New("person is not under review")
 )
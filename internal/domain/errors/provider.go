@@ -0,0 +1,85 @@
+package errors
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ProviderError is the failure of the provider asked for an attribute. Err wraps one of
+// ErrProviderUnavailable, ErrProviderRateLimited, ErrProviderBadResponse or ErrNameUnknown
+// when the kind of the failure is known.
+type ProviderError struct {
+	Attribute string
+	Provider  string
+	// Status is the HTTP status the provider answered with, 0 when there was no answer.
+	Status int
+	// RetryAfter is how long the provider asked to wait before the next request.
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *ProviderError) Error() string {
+	var b strings.Builder
+	if e.Attribute != "" {
+		b.WriteString(e.Attribute + " (" + e.Provider + ")")
+	} else {
+		b.WriteString(e.Provider)
+	}
+	if e.Status != 0 {
+		b.WriteString(": upstream status " + strconv.Itoa(e.Status))
+	}
+	b.WriteString(": " + e.Err.Error())
+	return b.String()
+}
+
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
+
+// EnrichError collects the failure of every attribute that could not be predicted.
+// errors.Is and errors.As look through all of them.
+type EnrichError []*ProviderError
+
+func (e EnrichError) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e EnrichError) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, err := range e {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// Providers returns the names of the providers that failed.
+func (e EnrichError) Providers() []string {
+	providers := make([]string, 0, len(e))
+	for _, err := range e {
+		providers = append(providers, err.Provider)
+	}
+	return providers
+}
+
+// RetryAfter returns the longest wait any provider failure in err asked for.
+func RetryAfter(err error) time.Duration {
+	var enrichErr EnrichError
+	if errors.As(err, &enrichErr) {
+		var longest time.Duration
+		for _, e := range enrichErr {
+			longest = max(longest, e.RetryAfter)
+		}
+		return longest
+	}
+	var providerErr *ProviderError
+	if errors.As(err, &providerErr) {
+		return providerErr.RetryAfter
+	}
+	return 0
+}
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// EnrichmentLog is a raw provider answer the predictions of a person were made from.
+type EnrichmentLog struct {
+	Id        int64             `json:"id,string" db:"id"`
+	PersonId  int64             `json:"person_id,string" db:"person_id"`
+	Provider  string            `json:"provider" db:"provider"`
+	URL       string            `json:"url" db:"url"`
+	Status    int               `json:"status" db:"status"`
+	Headers   map[string]string `json:"headers" db:"-"`
+	Body      string            `json:"body" db:"body"`
+	LatencyMs int64             `json:"latency_ms" db:"latency_ms"`
+	CreatedAt time.Time         `json:"created_at" db:"created_at"`
+	// Cached marks an answer served from the cache, CreatedAt is when it was originally received.
+	Cached bool `json:"cached" db:"cached"`
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Operations recorded in the person history. Deleting a person is recorded when it is
+// deleted and once more, without the state after, when it is purged.
+const (
+	HistoryInsert = "insert"
+	HistoryUpdate = "update"
+	HistoryDelete = "delete"
+)
+
+// PersonHistory is a change of a person. Before and After are the person as the API
+// returns it, Before is null for an insert and After for a purge. Actor is taken from
+// the unverified X-Actor header of the request.
+type PersonHistory struct {
+	Id        int64           `json:"id,string" db:"id"`
+	PersonId  int64           `json:"person_id,string" db:"person_id"`
+	Operation string          `json:"operation" db:"operation"`
+	Before    json.RawMessage `json:"before" db:"-"`
+	After     json.RawMessage `json:"after" db:"-"`
+	Actor     string          `json:"actor" db:"actor"`
+	RequestId string          `json:"request_id" db:"request_id"`
+	CreatedAt time.Time       `json:"created_at" db:"created_at"`
+}
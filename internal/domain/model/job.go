@@ -0,0 +1,49 @@
+package model
+
+import "time"
+
+// Enrichment job statuses.
+const (
+	JobStatusQueued  = "queued"
+	JobStatusRunning = "running"
+	JobStatusDone    = "done"
+	JobStatusFailed  = "failed"
+	// JobStatusCancelled marks a queued job of a cancelled re-enrichment run.
+	JobStatusCancelled = "cancelled"
+)
+
+// Re-enrichment run statuses.
+const (
+	RunStatusRunning   = "running"
+	RunStatusDone      = "done"
+	RunStatusCancelled = "cancelled"
+)
+
+// Job is an asynchronous enrichment of a person.
+type Job struct {
+	Id        int64  `json:"id,string" db:"id"`
+	PersonId  int64  `json:"person_id,string" db:"person_id"`
+	CountryId string `json:"country_id" db:"country_id"`
+	// RunId is the re-enrichment run the job belongs to, nil for the enrichment of a new person.
+	RunId     *int64    `json:"run_id,string,omitempty" db:"run_id"`
+	Status    string    `json:"status" db:"status"`
+	Attempts  int       `json:"attempts" db:"attempts"`
+	Error     string    `json:"error,omitempty" db:"error"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// ReenrichRun is a bulk re-enrichment of stored persons, one job per person.
+// The counters report the progress of its jobs by status.
+type ReenrichRun struct {
+	Id        int64     `json:"id,string" db:"id"`
+	Status    string    `json:"status" db:"status"`
+	Total     int       `json:"total" db:"total"`
+	Queued    int       `json:"queued" db:"queued"`
+	Running   int       `json:"running" db:"running"`
+	Done      int       `json:"done" db:"done"`
+	Failed    int       `json:"failed" db:"failed"`
+	Cancelled int       `json:"cancelled" db:"cancelled"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
@@ -1,11 +1,68 @@
 package model
 
+import "time"
+
+// Person statuses of the enrichment pipeline.
+const (
+	PersonStatusPending  = "pending"
+	PersonStatusEnriched = "enriched"
+	PersonStatusFailed   = "failed"
+	// PersonStatusNeedsReview marks an enriched person whose predictions the review policy doubts.
+	PersonStatusNeedsReview = "needs_review"
+)
+
+// Sources of person attributes other than the providers, which are named after themselves.
+const (
+	// SourceManual marks an attribute given by a client or a reviewer.
+	SourceManual = "manual"
+	// SourceLocalRule marks an attribute inferred from the patronymic or surname.
+	SourceLocalRule = "local-rule"
+)
+
 type Person struct {
-	Id          int64  `json:"id,string" db:"id"`
-	Name        string `json:"name" db:"name"`
-	Surname     string `json:"surname" db:"surname"`
-	Patronymic  string `json:"patronymic" db:"patronymic"`
-	Age         int    `json:"age,string" db:"age" `
-	Gender      string `json:"gender" db:"gender"`
-	Nationality string `json:"nationality" db:"nationality"`
+	Id                     int64   `json:"id,string" db:"id"`
+	Name                   string  `json:"name" db:"name"`
+	Surname                string  `json:"surname" db:"surname"`
+	Patronymic             string  `json:"patronymic" db:"patronymic"`
+	NameLatin              string  `json:"name_latin" db:"name_latin"`
+	SurnameLatin           string  `json:"surname_latin" db:"surname_latin"`
+	PatronymicLatin        string  `json:"patronymic_latin" db:"patronymic_latin"`
+	Age                    *int    `json:"age,string" db:"age" `
+	AgeCount               int     `json:"age_count" db:"age_count"`
+	AgeStatus              string  `json:"age_status" db:"age_status"`
+	AgeCountryId           string  `json:"age_country_id" db:"age_country_id"`
+	AgeSource              string  `json:"age_source" db:"age_source"`
+	Gender                 *string `json:"gender" db:"gender"`
+	GenderProbability      float64 `json:"gender_probability" db:"gender_probability"`
+	GenderCount            int     `json:"gender_count" db:"gender_count"`
+	GenderStatus           string  `json:"gender_status" db:"gender_status"`
+	GenderCountryId        string  `json:"gender_country_id" db:"gender_country_id"`
+	GenderSource           string  `json:"gender_source" db:"gender_source"`
+	Nationality            *string `json:"nationality" db:"nationality"`
+	NationalityProbability float64 `json:"nationality_probability" db:"nationality_probability"`
+	NationalityCount       int     `json:"nationality_count" db:"nationality_count"`
+	NationalityStatus      string  `json:"nationality_status" db:"nationality_status"`
+	NationalitySource      string  `json:"nationality_source" db:"nationality_source"`
+	Status                 string  `json:"status" db:"status"`
+	// EnrichedAt is when the providers were last asked, nil when they never answered.
+	EnrichedAt *time.Time `json:"enriched_at" db:"enriched_at"`
+	// ReviewReason lists the review policy conditions a person under review matched.
+	ReviewReason string `json:"review_reason,omitempty" db:"review_reason"`
+	// Verified attributes were set by a client or a reviewer and are never overwritten by the providers.
+	AgeVerified         bool `json:"age_verified" db:"age_verified"`
+	GenderVerified      bool `json:"gender_verified" db:"gender_verified"`
+	NationalityVerified bool `json:"nationality_verified" db:"nationality_verified"`
+	// Version grows with every change of the person, an update must be based on the current one.
+	Version int64 `json:"version" db:"version"`
+	// DeletedAt is when the person was deleted, it can be restored until it is purged.
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+
+	Nationalities []PersonNationality `json:"nationalities" db:"-"`
+}
+
+// PersonNationality is one country of the ranked nationality distribution, rank 1 being the most probable.
+type PersonNationality struct {
+	CountryId   string  `json:"country_id" db:"country_id"`
+	Probability float64 `json:"probability" db:"probability"`
+	Rank        int     `json:"rank" db:"rank"`
 }
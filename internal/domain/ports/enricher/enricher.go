@@ -1,13 +1,138 @@
 package enricher
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
+// Per-attribute enrichment statuses.
+const (
+	StatusOk            = "ok"
+	StatusUnknown       = "unknown"
+	StatusProviderError = "provider_error"
+	// StatusSkipped marks an attribute whose request was cancelled after another attribute failed.
+	StatusSkipped = "skipped"
+	// StatusNotRequested marks an attribute the caller asked not to predict.
+	StatusNotRequested = "not_requested"
+)
+
+// Attributes of a person the providers predict.
+const (
+	AttributeAge         = "age"
+	AttributeGender      = "gender"
+	AttributeNationality = "nationality"
+)
+
+// EnrichData holds the predicted attributes. An attribute that could not be
+// predicted is nil and its status tells whether the name is unknown to the
+// providers or the providers failed. The sources name the providers that answered.
 type EnrichData struct {
-	Age         int    `json:"age" db:"age"`
-	Gender      string `json:"gender" db:"gender"`
-	Nationality string `json:"nationality" db:"nationality"`
+	Age                    *int    `json:"age" db:"age"`
+	AgeCount               int     `json:"age_count" db:"age_count"`
+	AgeStatus              string  `json:"age_status" db:"age_status"`
+	AgeCountryId           string  `json:"age_country_id" db:"age_country_id"`
+	AgeSource              string  `json:"age_source" db:"age_source"`
+	Gender                 *string `json:"gender" db:"gender"`
+	GenderProbability      float64 `json:"gender_probability" db:"gender_probability"`
+	GenderCount            int     `json:"gender_count" db:"gender_count"`
+	GenderStatus           string  `json:"gender_status" db:"gender_status"`
+	GenderCountryId        string  `json:"gender_country_id" db:"gender_country_id"`
+	GenderSource           string  `json:"gender_source" db:"gender_source"`
+	Nationality            *string `json:"nationality" db:"nationality"`
+	NationalityProbability float64 `json:"nationality_probability" db:"nationality_probability"`
+	NationalityCount       int     `json:"nationality_count" db:"nationality_count"`
+	NationalityStatus      string  `json:"nationality_status" db:"nationality_status"`
+	NationalitySource      string  `json:"nationality_source" db:"nationality_source"`
+	// Nationalities is the full ranked distribution, Nationality is its first entry.
+	Nationalities []Country `json:"nationalities" db:"-"`
+	// Err explains why some attributes are missing.
+	Err error `json:"-" db:"-"`
+	// Responses are the raw provider answers the data was predicted from. They are kept
+	// with the cached data and marked as cached when it is served again.
+	Responses []ProviderResponse `json:"responses,omitempty" db:"-"`
+}
+
+// ProviderResponse is a raw answer of an upstream provider, kept to audit the predictions.
+type ProviderResponse struct {
+	Provider string `json:"provider"`
+	URL      string `json:"url"`
+	Status   int    `json:"status"`
+	// Headers holds the response headers worth keeping, such as the rate limit ones.
+	Headers    map[string]string `json:"headers"`
+	Body       string            `json:"body"`
+	Latency    time.Duration     `json:"latency"`
+	ReceivedAt time.Time         `json:"received_at"`
+	// Cached marks an answer served from the cache, it was received for an earlier call.
+	Cached bool `json:"cached"`
+}
+
+// Complete reports whether every attribute was predicted.
+func (d *EnrichData) Complete() bool {
+	return d.Age != nil && d.Gender != nil && d.Nationality != nil
 }
 
+// Failed reports whether a provider failed for some attribute, so asking again may help.
+func (d *EnrichData) Failed() bool {
+	for _, status := range []string{d.AgeStatus, d.GenderStatus, d.NationalityStatus} {
+		if status == StatusProviderError || status == StatusSkipped {
+			return true
+		}
+	}
+	return false
+}
+
+// BatchResult is the enrichment outcome for a single name of a batch. Err tells why
+// some attribute could not be predicted, Data then holds the others if any.
+type BatchResult struct {
+	Data *EnrichData
+	Err  error
+}
+
+// Enricher predicts the attributes of first names. A non-empty country id localizes
+// the predictions to that country where the providers support it. The attributes
+// listed in skip are not asked for and are left empty with StatusNotRequested.
 type Enricher interface {
-	Enrich(context.Context, string) (*EnrichData, error)
+	Enrich(ctx context.Context, name string, countryId string, skip ...string) (*EnrichData, error)
+	EnrichBatch(ctx context.Context, names []string, countryId string) (map[string]BatchResult, error)
+}
+
+// ProviderStatus is the circuit breaker state of an upstream provider.
+type ProviderStatus struct {
+	Provider string `json:"provider"`
+	State    string `json:"state"`
+}
+
+type StatusReporter interface {
+	ProviderStatuses() []ProviderStatus
+}
+
+// ProviderQuota is the daily request usage of an upstream provider.
+type ProviderQuota struct {
+	Provider string    `json:"provider" db:"provider"`
+	Day      time.Time `json:"day" db:"day"`
+	Used     int       `json:"used" db:"used"`
+	// Budget is our own daily cap on requests, 0 when there is none.
+	Budget int `json:"budget" db:"-"`
+	// Limit, Remaining and ResetAt are reported by the provider, nil until it answered today.
+	Limit     *int       `json:"limit" db:"rate_limit"`
+	Remaining *int       `json:"remaining" db:"remaining"`
+	ResetAt   *time.Time `json:"reset_at" db:"reset_at"`
+}
+
+type QuotaReporter interface {
+	ProviderQuotas() []ProviderQuota
+}
+
+// CoalescingStats counts how enrichment calls were served.
+type CoalescingStats struct {
+	// Calls is the number of Enrich calls.
+	Calls int64 `json:"calls"`
+	// Coalesced is the number of Enrich calls that joined a call already in flight.
+	Coalesced int64 `json:"coalesced"`
+	// Upstream is the number of calls made to the wrapped enricher.
+	Upstream int64 `json:"upstream"`
+}
+
+type CoalescingReporter interface {
+	CoalescingStats() CoalescingStats
 }
@@ -0,0 +1,73 @@
+package enricher
+
+import "context"
+
+// Count is the number of samples the prediction is based on. CountryId is the country
+// the prediction was localized to, empty for a worldwide one. Provider names the
+// provider that answered.
+type Age struct {
+	Age       int    `json:"age"`
+	Count     int    `json:"count"`
+	CountryId string `json:"country_id"`
+	Provider  string `json:"provider"`
+}
+
+type Gender struct {
+	Gender      string  `json:"gender"`
+	Probability float64 `json:"probability"`
+	Count       int     `json:"count"`
+	CountryId   string  `json:"country_id"`
+	Provider    string  `json:"provider"`
+}
+
+type Country struct {
+	CountryId   string  `json:"country_id"`
+	Probability float64 `json:"probability"`
+}
+
+// Nationality holds the predicted countries ranked from the most probable one.
+type Nationality struct {
+	Country  []Country `json:"country"`
+	Count    int       `json:"count"`
+	Provider string    `json:"provider"`
+}
+
+// AgeProvider predicts the age for a first name, localized to countryId when it is
+// not empty and the provider supports it. The batch method returns results only for
+// the names it could answer.
+type AgeProvider interface {
+	Name() string
+	Age(ctx context.Context, name string, countryId string) (*Age, error)
+	Ages(ctx context.Context, names []string, countryId string) (map[string]*Age, error)
+}
+
+// GenderProvider predicts the gender for a first name, localized to countryId when it
+// is not empty and the provider supports it. The batch method returns results only for
+// the names it could answer.
+type GenderProvider interface {
+	Name() string
+	Gender(ctx context.Context, name string, countryId string) (*Gender, error)
+	Genders(ctx context.Context, names []string, countryId string) (map[string]*Gender, error)
+}
+
+// NationalityProvider predicts the nationality for a first name. The batch method
+// returns results only for the names it could answer.
+type NationalityProvider interface {
+	Name() string
+	Nationality(ctx context.Context, name string) (*Nationality, error)
+	Nationalities(ctx context.Context, names []string) (map[string]*Nationality, error)
+}
+
+// Inference is what the local name rules make of a whole name. An attribute no rule
+// matched is empty, the confidences are in [0, 1].
+type Inference struct {
+	Gender                string  `json:"gender"`
+	GenderConfidence      float64 `json:"gender_confidence"`
+	Nationality           string  `json:"nationality"`
+	NationalityConfidence float64 `json:"nationality_confidence"`
+}
+
+// NameRules infers attributes from the patronymic and surname without asking any provider.
+type NameRules interface {
+	Infer(surname string, patronymic string) Inference
+}
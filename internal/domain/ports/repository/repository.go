@@ -2,13 +2,98 @@ package repository
 
 import (
 	"context"
+	"fmt"
+	"github.com/Kosodaka/enricher-service/internal/domain/dto"
 	"github.com/Kosodaka/enricher-service/internal/domain/model"
+	"github.com/Kosodaka/enricher-service/internal/domain/ports/enricher"
+	"time"
 )
 
+// AnyVersion lets an update apply to whatever version the person is at, as If-Match: * asks.
+const AnyVersion int64 = -1
+
+// ErrVersionConflict means the person was changed since the version an update was based on.
+type ErrVersionConflict struct {
+	Id       int64
+	Expected int64
+	Current  int64
+}
+
+func (e *ErrVersionConflict) Error() string {
+	return fmt.Sprintf("person %d is at version %d, not %d", e.Id, e.Current, e.Expected)
+}
+
 type PersonRepository interface {
 	AddPerson(context.Context, *model.Person) (int, error)
-	GetPerson(context.Context, int) (*model.Person, error)
-	GetPersons(context.Context, *model.Person) ([]model.Person, error)
+	// GetPerson returns the person, a deleted one only when includeDeleted is set. A missing
+	// person is the domain ErrPersonNotFound.
+	GetPerson(ctx context.Context, id int, includeDeleted bool) (*model.Person, error)
+	GetPersons(context.Context, *dto.PersonFilter) (*dto.PersonPage, error)
+	// SearchPersons returns at most limit persons whose full name is similar to the query, the most similar first.
+	SearchPersons(ctx context.Context, query string, limit int, includeDeleted bool) ([]dto.PersonMatch, error)
+	// UpdatePerson saves the person if it is still at its version, or at any with AnyVersion,
+	// and advances the version.
+	// It returns *ErrVersionConflict when the person was changed meanwhile.
 	UpdatePerson(context.Context, *model.Person) error
+	// DeletePerson hides the person until it is restored or purged.
 	DeletePerson(context.Context, int) error
+	// RestorePerson brings back a deleted person that was not purged yet.
+	RestorePerson(ctx context.Context, id int) error
+	// PurgePersons removes the persons deleted before the given time for good and reports how many there were.
+	PurgePersons(ctx context.Context, before time.Time) (int64, error)
+	// UpdateEnrichment saves the predicted attributes of an existing person if it is still at its version.
+	// It returns *ErrVersionConflict when the person was changed since it was read.
+	UpdateEnrichment(context.Context, *model.Person) error
+	// GetPersonsToReview returns the persons the review policy sent to review.
+	GetPersonsToReview(context.Context) ([]model.Person, error)
+	// SaveReview saves the confirmed or corrected attributes of a person under review if it is still
+	// at its version. It returns *ErrVersionConflict when the person was changed since it was read.
+	SaveReview(context.Context, *model.Person) error
+	// GetPersonHistory returns the recorded changes of the person, the oldest first.
+	GetPersonHistory(ctx context.Context, id int) ([]model.PersonHistory, error)
+	// GetPersonAsOf reconstructs the person as it was at the given time from its history.
+	GetPersonAsOf(ctx context.Context, id int, asOf time.Time) (*model.Person, error)
+}
+
+// EnrichmentCacheRepository persists enrichment results keyed by normalized name.
+// GetEnrichment returns nil, nil when there is no entry updated after notBefore.
+type EnrichmentCacheRepository interface {
+	GetEnrichment(ctx context.Context, name string, notBefore time.Time) (*enricher.EnrichData, error)
+	SaveEnrichment(ctx context.Context, name string, data *enricher.EnrichData) error
+}
+
+// JobRepository is a queue of asynchronous enrichment jobs.
+type JobRepository interface {
+	// AddPersonJob saves a pending person together with a queued job enriching it localized to countryId.
+	AddPersonJob(ctx context.Context, person *model.Person, countryId string) (personId int, jobId int, err error)
+	GetJob(ctx context.Context, id int) (*model.Job, error)
+	// ClaimJob locks the oldest queued job for the caller and returns nil, nil when there is none.
+	ClaimJob(ctx context.Context) (*model.Job, error)
+	// CompleteJob saves the enriched person and marks the job done, or returns
+	// *ErrVersionConflict when the person was changed since it was read.
+	CompleteJob(ctx context.Context, job *model.Job, person *model.Person) error
+	// FailJob puts the job back into the queue when retry is set, otherwise marks it and its person failed.
+	FailJob(ctx context.Context, job *model.Job, reason string, retry bool) error
+	// DeferJob puts the job back into the queue until the given time without using up an attempt.
+	DeferJob(ctx context.Context, job *model.Job, until time.Time, reason string) error
+	// AddReenrichRun queues a job for every person matching the filter.
+	AddReenrichRun(ctx context.Context, filter *dto.ReenrichFilter) (*model.ReenrichRun, error)
+	GetReenrichRun(ctx context.Context, id int) (*model.ReenrichRun, error)
+	// CancelReenrichRun drops the queued jobs of the run, the running ones are left to finish.
+	CancelReenrichRun(ctx context.Context, id int) (*model.ReenrichRun, error)
+}
+
+// ProviderUsageRepository keeps the daily request counters of the providers across restarts.
+type ProviderUsageRepository interface {
+	GetProviderUsage(ctx context.Context, day time.Time) ([]enricher.ProviderQuota, error)
+	SaveProviderUsage(ctx context.Context, usage []enricher.ProviderQuota) error
+}
+
+// EnrichmentLogRepository archives the raw provider answers per person.
+type EnrichmentLogRepository interface {
+	AddEnrichmentLog(ctx context.Context, personId int, responses []enricher.ProviderResponse) error
+	// GetEnrichmentLog returns the answers of the person, the oldest first.
+	GetEnrichmentLog(ctx context.Context, personId int) ([]model.EnrichmentLog, error)
+	// PruneEnrichmentLog deletes the answers received before the given time and reports how many there were.
+	PruneEnrichmentLog(ctx context.Context, before time.Time) (int64, error)
 }
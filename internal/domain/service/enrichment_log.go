@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"github.com/Kosodaka/enricher-service/internal/domain/model"
+	"github.com/Kosodaka/enricher-service/internal/domain/ports/enricher"
+	"github.com/Kosodaka/enricher-service/internal/domain/ports/repository"
+	"log/slog"
+	"time"
+)
+
+func SetEnrichmentLogRepository(r repository.EnrichmentLogRepository) Option {
+	return func(o *Options) error {
+		o.EnrichmentLogRepository = r
+		return nil
+	}
+}
+
+// archive saves the raw provider answers of the person. Losing them must not fail
+// the enrichment, so errors are only logged.
+func (s service) archive(ctx context.Context, personId int, responses []enricher.ProviderResponse) {
+	if s.opts.EnrichmentLogRepository == nil || len(responses) == 0 {
+		return
+	}
+	if err := s.opts.EnrichmentLogRepository.AddEnrichmentLog(ctx, personId, responses); err != nil {
+		s.opts.Logger.Error("failed to archive provider responses", slog.Int("id", personId), slog.Any("error", err))
+	}
+}
+
+// GetEnrichmentLog returns the raw provider answers the person was enriched from.
+func (s service) GetEnrichmentLog(ctx context.Context, id int) ([]model.EnrichmentLog, error) {
+	if err := s.opts.Validator.ValidateId(id); err != nil {
+		return nil, err
+	}
+	if _, err := s.opts.Repository.GetPerson(ctx, id, false); err != nil {
+		return nil, err
+	}
+	if s.opts.EnrichmentLogRepository == nil {
+		return []model.EnrichmentLog{}, nil
+	}
+	return s.opts.EnrichmentLogRepository.GetEnrichmentLog(ctx, id)
+}
+
+// PruneEnrichmentLog deletes the provider answers received before the given time.
+func (s service) PruneEnrichmentLog(ctx context.Context, before time.Time) (int64, error) {
+	op := "service.PruneEnrichmentLog"
+	logger := s.opts.Logger.With("operation", op)
+	if s.opts.EnrichmentLogRepository == nil {
+		return 0, nil
+	}
+	pruned, err := s.opts.EnrichmentLogRepository.PruneEnrichmentLog(ctx, before)
+	if err != nil {
+		logger.Debug("failed to prune enrichment log", slog.Any("error", err))
+		return 0, err
+	}
+	logger.Debug("enrichment log was successfully pruned", slog.Int64("pruned", pruned))
+	return pruned, nil
+}
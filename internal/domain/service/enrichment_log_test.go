@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/Kosodaka/enricher-service/internal/domain/dto"
+	domainErr "github.com/Kosodaka/enricher-service/internal/domain/errors"
+	"github.com/Kosodaka/enricher-service/internal/domain/model"
+	"github.com/Kosodaka/enricher-service/internal/domain/ports/enricher"
+	"github.com/Kosodaka/enricher-service/pkg/logger"
+	mock_enricher "github.com/Kosodaka/enricher-service/pkg/mocks/api/enricher"
+	mock_repository "github.com/Kosodaka/enricher-service/pkg/mocks/api/repository"
+	"github.com/Kosodaka/enricher-service/pkg/ptr"
+	"github.com/Kosodaka/enricher-service/pkg/validator"
+	"github.com/golang/mock/gomock"
+	"testing"
+)
+
+func TestService_AddPersonArchivesResponses(t *testing.T) {
+	responses := []enricher.ProviderResponse{
+		{Provider: "agify", Status: 200, Body: `{"age":40}`},
+		{Provider: "genderize", Status: 200, Body: `{"gender":"female"}`},
+		{Provider: "nationalize", Status: 200, Body: `{"country":[{"country_id":"RU"}]}`},
+	}
+	cases := []struct {
+		name       string
+		archiveErr error
+	}{
+		{
+			name: "responses are archived for the new person",
+		}, {
+			name:       "a failed archive does not fail the person",
+			archiveErr: errors.New("db is down"),
+		},
+	}
+	for _, testCases := range cases {
+		t.Run(testCases.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			persons := mock_repository.NewMockPersonRepository(ctrl)
+			logs := mock_repository.NewMockEnrichmentLogRepository(ctrl)
+			e := mock_enricher.NewMockEnricher(ctrl)
+			svc := NewService()
+			svc.Init(SetLogger(logger.SetupLogger("test")), SetValidator(validator.NewValidator()),
+				SetRepository(persons), SetEnricher(e), SetEnrichmentLogRepository(logs))
+
+			e.EXPECT().Enrich(gomock.Any(), "Olga", "").Return(&enricher.EnrichData{
+				Age:         ptr.To(40),
+				Gender:      ptr.To("female"),
+				Nationality: ptr.To("RU"),
+				Responses:   responses,
+			}, nil)
+			persons.EXPECT().AddPerson(gomock.Any(), gomock.Any()).Return(5, nil)
+			logs.EXPECT().AddEnrichmentLog(gomock.Any(), 5, responses).Return(testCases.archiveErr)
+
+			id, err := svc.AddPerson(context.Background(), &dto.AddPersonDTO{Name: "Olga", Surname: "Ivanova"})
+			if err != nil || id != 5 {
+				t.Fatalf("got %d, %v, want 5", id, err)
+			}
+		})
+	}
+}
+
+func TestService_ReenrichPersonArchivesFailure(t *testing.T) {
+	responses := []enricher.ProviderResponse{{Provider: "genderize", Status: 503}}
+
+	ctrl := gomock.NewController(t)
+	persons := mock_repository.NewMockPersonRepository(ctrl)
+	logs := mock_repository.NewMockEnrichmentLogRepository(ctrl)
+	e := mock_enricher.NewMockEnricher(ctrl)
+	svc := NewService()
+	svc.Init(SetLogger(logger.SetupLogger("test")), SetValidator(validator.NewValidator()),
+		SetRepository(persons), SetEnricher(e), SetEnrichmentLogRepository(logs))
+
+	persons.EXPECT().GetPerson(gomock.Any(), 7, false).Return(&model.Person{Id: 7, Name: "Olga", NameLatin: "Olga"}, nil)
+	e.EXPECT().Enrich(gomock.Any(), "Olga", "").Return(&enricher.EnrichData{
+		GenderStatus: enricher.StatusProviderError,
+		Err:          domainErr.ErrProviderUnavailable,
+		Responses:    responses,
+	}, nil)
+	// The answers of a failed enrichment are kept too, they tell why it failed.
+	logs.EXPECT().AddEnrichmentLog(gomock.Any(), 7, responses).Return(nil)
+
+	if _, err := svc.ReenrichPerson(context.Background(), 7); !errors.Is(err, domainErr.ErrProviderUnavailable) {
+		t.Fatalf("got %v, want %v", err, domainErr.ErrProviderUnavailable)
+	}
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	domainErr "github.com/Kosodaka/enricher-service/internal/domain/errors"
+	"github.com/Kosodaka/enricher-service/internal/domain/model"
+	"log/slog"
+	"time"
+)
+
+// GetPersonHistory returns who changed the person, when and how, the oldest change first.
+func (s service) GetPersonHistory(ctx context.Context, id int) ([]model.PersonHistory, error) {
+	op := "service.GetPersonHistory"
+	logger := s.opts.Logger.With("operation", op)
+	if err := s.opts.Validator.ValidateId(id); err != nil {
+		return nil, err
+	}
+	history, err := s.opts.Repository.GetPersonHistory(ctx, id)
+	if err != nil {
+		logger.Debug("failed to get person history", slog.Int("id", id), slog.Any("error", err))
+		return nil, err
+	}
+	logger.Debug("person history was successfully got", slog.Int("id", id), slog.Int("changes", len(history)))
+	return history, nil
+}
+
+// GetPersonAsOf returns the person as it was at the given time. A person deleted by then
+// is returned only when includeDeleted is set.
+func (s service) GetPersonAsOf(ctx context.Context, id int, asOf time.Time, includeDeleted bool) (*model.Person, error) {
+	op := "service.GetPersonAsOf"
+	logger := s.opts.Logger.With("operation", op)
+	if err := s.opts.Validator.ValidateId(id); err != nil {
+		return nil, err
+	}
+	person, err := s.opts.Repository.GetPersonAsOf(ctx, id, asOf)
+	if err != nil {
+		logger.Debug("failed to reconstruct person", slog.Int("id", id), slog.Any("error", err))
+		return nil, err
+	}
+	if person.DeletedAt != nil && !includeDeleted {
+		return nil, domainErr.ErrPersonNotFound
+	}
+	logger.Debug("person was successfully reconstructed", slog.Int("id", id), slog.Time("as_of", asOf))
+	return person, nil
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	domainErr "github.com/Kosodaka/enricher-service/internal/domain/errors"
+	"github.com/Kosodaka/enricher-service/internal/domain/model"
+	"github.com/Kosodaka/enricher-service/pkg/logger"
+	mock_repository "github.com/Kosodaka/enricher-service/pkg/mocks/api/repository"
+	"github.com/Kosodaka/enricher-service/pkg/ptr"
+	"github.com/Kosodaka/enricher-service/pkg/validator"
+	"github.com/golang/mock/gomock"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestService_GetPersonAsOf(t *testing.T) {
+	asOf := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	deletedAt := asOf.Add(-time.Hour)
+	cases := []struct {
+		name           string
+		id             int
+		stored         *model.Person
+		storedErr      error
+		includeDeleted bool
+		output         *model.Person
+		err            error
+	}{
+		{
+			name:   "past state",
+			id:     5,
+			stored: &model.Person{Id: 5, Name: "Olga", Nationality: ptr.To("RU"), Version: 2},
+			output: &model.Person{Id: 5, Name: "Olga", Nationality: ptr.To("RU"), Version: 2},
+		}, {
+			name:   "deleted by then",
+			id:     5,
+			stored: &model.Person{Id: 5, Name: "Olga", DeletedAt: &deletedAt},
+			err:    domainErr.ErrPersonNotFound,
+		}, {
+			name:           "deleted by then included",
+			id:             5,
+			stored:         &model.Person{Id: 5, Name: "Olga", DeletedAt: &deletedAt},
+			includeDeleted: true,
+			output:         &model.Person{Id: 5, Name: "Olga", DeletedAt: &deletedAt},
+		}, {
+			name:      "no history by then",
+			id:        5,
+			storedErr: domainErr.ErrPersonNotFound,
+			err:       domainErr.ErrPersonNotFound,
+		}, {
+			name: "invalid id",
+			id:   0,
+			err:  domainErr.InvalidId,
+		},
+	}
+	for _, testCases := range cases {
+		t.Run(testCases.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			persons := mock_repository.NewMockPersonRepository(ctrl)
+			if testCases.stored != nil || testCases.storedErr != nil {
+				persons.EXPECT().GetPersonAsOf(gomock.Any(), testCases.id, asOf).Return(testCases.stored, testCases.storedErr)
+			}
+			svc := NewService()
+			svc.Init(SetLogger(logger.SetupLogger("test")), SetValidator(validator.NewValidator()), SetRepository(persons))
+
+			result, err := svc.GetPersonAsOf(context.Background(), testCases.id, asOf, testCases.includeDeleted)
+			if !reflect.DeepEqual(result, testCases.output) {
+				t.Errorf("got %v, want %v", result, testCases.output)
+			}
+			if !reflect.DeepEqual(err, testCases.err) {
+				t.Errorf("got %v, want %v", err, testCases.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/Kosodaka/enricher-service/internal/domain/dto"
+	domainErr "github.com/Kosodaka/enricher-service/internal/domain/errors"
+	"github.com/Kosodaka/enricher-service/internal/domain/model"
+	"github.com/Kosodaka/enricher-service/internal/domain/ports/enricher"
+	"github.com/Kosodaka/enricher-service/internal/domain/ports/repository"
+	"log/slog"
+	"time"
+)
+
+// rateLimitedJobDelay is how long a job waits for the provider quota when the provider did not say.
+const rateLimitedJobDelay = time.Minute
+
+func (s *service) JobRepository() repository.JobRepository {
+	return s.opts.JobRepository
+}
+
+func SetJobRepository(r repository.JobRepository) Option {
+	return func(o *Options) error {
+		o.JobRepository = r
+		return nil
+	}
+}
+
+func SetJobAttempts(attempts int) Option {
+	return func(o *Options) error {
+		if attempts < 1 {
+			attempts = 1
+		}
+		o.JobAttempts = attempts
+		return nil
+	}
+}
+
+// AddPersonAsync saves a pending person and queues its enrichment.
+func (s service) AddPersonAsync(ctx context.Context, data *dto.AddPersonDTO) (int, int, error) {
+	op := "service.AddPersonAsync"
+	logger := s.opts.Logger.With("operation", op)
+	if err := s.opts.Validator.ValidateDataToAdd(data); err != nil {
+		return 0, 0, err
+	}
+	personModel := &model.Person{
+		Name:       data.Name,
+		Surname:    data.Surname,
+		Patronymic: data.Patronymic,
+		Status:     model.PersonStatusPending,
+	}
+	latinize(personModel)
+	setManual(personModel, data.Age, data.Gender, data.Nationality)
+	personId, jobId, err := s.opts.JobRepository.AddPersonJob(ctx, personModel, s.countryId(data.CountryId))
+	if err != nil {
+		logger.Debug("failed to queue person", slog.Any("error", err))
+		return 0, 0, err
+	}
+	logger.Debug("person was successfully queued", slog.Int("id", personId), slog.Int("job_id", jobId))
+	return personId, jobId, nil
+}
+
+func (s service) GetJob(ctx context.Context, id int) (*model.Job, error) {
+	if err := s.opts.Validator.ValidateId(id); err != nil {
+		return nil, err
+	}
+	return s.opts.JobRepository.GetJob(ctx, id)
+}
+
+// ProcessJob enriches the person of the next queued job. It reports false when the queue is empty.
+func (s service) ProcessJob(ctx context.Context) (bool, error) {
+	op := "service.ProcessJob"
+	logger := s.opts.Logger.With("operation", op)
+	job, err := s.opts.JobRepository.ClaimJob(ctx)
+	if err != nil {
+		return false, err
+	}
+	if job == nil {
+		return false, nil
+	}
+	logger = logger.With(slog.Int64("job_id", job.Id), slog.Int("attempt", job.Attempts))
+
+	person, err := s.opts.Repository.GetPerson(ctx, int(job.PersonId), false)
+	if err == nil {
+		var responses []enricher.ProviderResponse
+		// Jobs of re-enrichment runs refresh the predictions, new persons may use cached ones.
+		responses, err = s.enrich(ctx, person, s.countryId(job.CountryId), job.RunId != nil)
+		s.archive(ctx, int(job.PersonId), responses)
+	}
+	if err == nil && job.RunId == nil {
+		// Only new persons are reviewed, re-enrichment runs leave the review state alone.
+		s.review(person)
+	}
+	if err == nil {
+		err = s.opts.JobRepository.CompleteJob(ctx, job, person)
+	}
+	if err == nil {
+		logger.Debug("job was successfully done")
+		return true, nil
+	}
+
+	if errors.Is(err, domainErr.ErrProviderRateLimited) {
+		// Waiting for the provider quota to come back is not a failed attempt.
+		wait := domainErr.RetryAfter(err)
+		if wait <= 0 {
+			wait = rateLimitedJobDelay
+		}
+		logger.Debug("job deferred", slog.Any("error", err), slog.Duration("wait", wait))
+		if deferErr := s.opts.JobRepository.DeferJob(ctx, job, time.Now().Add(wait), err.Error()); deferErr != nil {
+			return true, deferErr
+		}
+		return true, nil
+	}
+
+	retry := job.Attempts < s.opts.JobAttempts && !errors.Is(err, domainErr.ErrNameUnknown)
+	logger.Debug("job failed", slog.Any("error", err), slog.Bool("retry", retry))
+	if failErr := s.opts.JobRepository.FailJob(ctx, job, err.Error(), retry); failErr != nil {
+		return true, failErr
+	}
+	return true, nil
+}
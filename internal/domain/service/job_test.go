@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"errors"
+	domainErr "github.com/Kosodaka/enricher-service/internal/domain/errors"
+	"github.com/Kosodaka/enricher-service/internal/domain/model"
+	"github.com/Kosodaka/enricher-service/internal/domain/ports/enricher"
+	"github.com/Kosodaka/enricher-service/internal/domain/ports/repository"
+	"github.com/Kosodaka/enricher-service/pkg/logger"
+	mock_enricher "github.com/Kosodaka/enricher-service/pkg/mocks/api/enricher"
+	mock_repository "github.com/Kosodaka/enricher-service/pkg/mocks/api/repository"
+	"github.com/Kosodaka/enricher-service/pkg/ptr"
+	"github.com/Kosodaka/enricher-service/pkg/validator"
+	"github.com/golang/mock/gomock"
+	"testing"
+	"time"
+)
+
+func TestService_ProcessJob(t *testing.T) {
+	enriched := &enricher.EnrichData{
+		Age:               ptr.To(30),
+		AgeStatus:         enricher.StatusOk,
+		Gender:            ptr.To("female"),
+		GenderStatus:      enricher.StatusOk,
+		Nationality:       ptr.To("UA"),
+		NationalityStatus: enricher.StatusOk,
+		Nationalities:     []enricher.Country{{CountryId: "UA", Probability: 0.7}},
+	}
+	cases := []struct {
+		name        string
+		job         *model.Job
+		preparation func(jobs *mock_repository.MockJobRepository, persons *mock_repository.MockPersonRepository, e *mock_enricher.MockEnricher, job *model.Job)
+		processed   bool
+	}{
+		{
+			name: "empty queue",
+			preparation: func(jobs *mock_repository.MockJobRepository, persons *mock_repository.MockPersonRepository, e *mock_enricher.MockEnricher, job *model.Job) {
+				jobs.EXPECT().ClaimJob(gomock.Any()).Return(nil, nil)
+			},
+			processed: false,
+		}, {
+			name: "enriched",
+			job:  &model.Job{Id: 1, PersonId: 7, Attempts: 1},
+			preparation: func(jobs *mock_repository.MockJobRepository, persons *mock_repository.MockPersonRepository, e *mock_enricher.MockEnricher, job *model.Job) {
+				jobs.EXPECT().ClaimJob(gomock.Any()).Return(job, nil)
+				persons.EXPECT().GetPerson(gomock.Any(), 7, false).Return(&model.Person{Id: 7, Name: "Olga", Status: model.PersonStatusPending}, nil)
+				e.EXPECT().Enrich(gomock.Any(), "Olga", "").Return(enriched, nil)
+				jobs.EXPECT().CompleteJob(gomock.Any(), job, gomock.Any()).DoAndReturn(
+					func(ctx context.Context, job *model.Job, person *model.Person) error {
+						if person.Status != model.PersonStatusEnriched || *person.Nationality != "UA" || len(person.Nationalities) != 1 {
+							t.Errorf("unexpected person %+v", person)
+						}
+						return nil
+					})
+			},
+			processed: true,
+		}, {
+			name: "provider error is retried",
+			job:  &model.Job{Id: 2, PersonId: 8, Attempts: 1},
+			preparation: func(jobs *mock_repository.MockJobRepository, persons *mock_repository.MockPersonRepository, e *mock_enricher.MockEnricher, job *model.Job) {
+				jobs.EXPECT().ClaimJob(gomock.Any()).Return(job, nil)
+				persons.EXPECT().GetPerson(gomock.Any(), 8, false).Return(&model.Person{Id: 8, Name: "Olga"}, nil)
+				e.EXPECT().Enrich(gomock.Any(), "Olga", "").Return(nil, domainErr.ErrProviderUnavailable)
+				jobs.EXPECT().FailJob(gomock.Any(), job, domainErr.ErrProviderUnavailable.Error(), true).Return(nil)
+			},
+			processed: true,
+		}, {
+			name: "person changed meanwhile is retried",
+			job:  &model.Job{Id: 6, PersonId: 12, Attempts: 1},
+			preparation: func(jobs *mock_repository.MockJobRepository, persons *mock_repository.MockPersonRepository, e *mock_enricher.MockEnricher, job *model.Job) {
+				conflict := &repository.ErrVersionConflict{Id: 12, Expected: 1, Current: 2}
+				jobs.EXPECT().ClaimJob(gomock.Any()).Return(job, nil)
+				persons.EXPECT().GetPerson(gomock.Any(), 12, false).Return(&model.Person{Id: 12, Name: "Olga", Version: 1}, nil)
+				e.EXPECT().Enrich(gomock.Any(), "Olga", "").Return(enriched, nil)
+				jobs.EXPECT().CompleteJob(gomock.Any(), job, gomock.Any()).Return(conflict)
+				jobs.EXPECT().FailJob(gomock.Any(), job, conflict.Error(), true).Return(nil)
+			},
+			processed: true,
+		}, {
+			name: "last attempt fails the job",
+			job:  &model.Job{Id: 3, PersonId: 9, Attempts: 3},
+			preparation: func(jobs *mock_repository.MockJobRepository, persons *mock_repository.MockPersonRepository, e *mock_enricher.MockEnricher, job *model.Job) {
+				jobs.EXPECT().ClaimJob(gomock.Any()).Return(job, nil)
+				persons.EXPECT().GetPerson(gomock.Any(), 9, false).Return(&model.Person{Id: 9, Name: "Olga"}, nil)
+				e.EXPECT().Enrich(gomock.Any(), "Olga", "").Return(nil, domainErr.ErrProviderUnavailable)
+				jobs.EXPECT().FailJob(gomock.Any(), job, domainErr.ErrProviderUnavailable.Error(), false).Return(nil)
+			},
+			processed: true,
+		}, {
+			name: "rate limited job waits for the quota",
+			job:  &model.Job{Id: 5, PersonId: 11, Attempts: 3},
+			preparation: func(jobs *mock_repository.MockJobRepository, persons *mock_repository.MockPersonRepository, e *mock_enricher.MockEnricher, job *model.Job) {
+				rateLimited := domainErr.EnrichError{{Attribute: "age", Provider: "agify", RetryAfter: time.Hour, Err: domainErr.ErrProviderRateLimited}}
+				jobs.EXPECT().ClaimJob(gomock.Any()).Return(job, nil)
+				persons.EXPECT().GetPerson(gomock.Any(), 11, false).Return(&model.Person{Id: 11, Name: "Olga"}, nil)
+				e.EXPECT().Enrich(gomock.Any(), "Olga", "").Return(&enricher.EnrichData{Err: rateLimited}, nil)
+				jobs.EXPECT().DeferJob(gomock.Any(), job, gomock.Any(), rateLimited.Error()).DoAndReturn(
+					func(ctx context.Context, job *model.Job, until time.Time, reason string) error {
+						if wait := time.Until(until); wait < 59*time.Minute || wait > time.Hour {
+							t.Errorf("got wait %s, want an hour", wait)
+						}
+						return nil
+					})
+			},
+			processed: true,
+		}, {
+			name: "unknown name is not retried",
+			job:  &model.Job{Id: 4, PersonId: 10, Attempts: 1},
+			preparation: func(jobs *mock_repository.MockJobRepository, persons *mock_repository.MockPersonRepository, e *mock_enricher.MockEnricher, job *model.Job) {
+				jobs.EXPECT().ClaimJob(gomock.Any()).Return(job, nil)
+				persons.EXPECT().GetPerson(gomock.Any(), 10, false).Return(&model.Person{Id: 10, Name: "Xyzzy"}, nil)
+				e.EXPECT().Enrich(gomock.Any(), "Xyzzy", "").Return(&enricher.EnrichData{Err: domainErr.ErrNameUnknown}, nil)
+				jobs.EXPECT().FailJob(gomock.Any(), job, domainErr.ErrNameUnknown.Error(), false).Return(nil)
+			},
+			processed: true,
+		},
+	}
+	for _, testCases := range cases {
+		t.Run(testCases.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			jobs := mock_repository.NewMockJobRepository(ctrl)
+			persons := mock_repository.NewMockPersonRepository(ctrl)
+			e := mock_enricher.NewMockEnricher(ctrl)
+			testCases.preparation(jobs, persons, e, testCases.job)
+
+			svc := NewService()
+			svc.Init(SetLogger(logger.SetupLogger("test")), SetValidator(validator.NewValidator()), SetRepository(persons),
+				SetEnricher(e), SetJobRepository(jobs), SetJobAttempts(3))
+
+			processed, err := svc.ProcessJob(context.Background())
+			if err != nil {
+				t.Errorf("unexpected error %v", err)
+			}
+			if processed != testCases.processed {
+				t.Errorf("got %v, want %v", processed, testCases.processed)
+			}
+		})
+	}
+}
+
+func TestService_ProcessJobClaimError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	jobs := mock_repository.NewMockJobRepository(ctrl)
+	claimErr := errors.New("connection refused")
+	jobs.EXPECT().ClaimJob(gomock.Any()).Return(nil, claimErr)
+
+	svc := NewService()
+	svc.Init(SetLogger(logger.SetupLogger("test")), SetJobRepository(jobs))
+	if _, err := svc.ProcessJob(context.Background()); !errors.Is(err, claimErr) {
+		t.Errorf("got %v, want %v", err, claimErr)
+	}
+}
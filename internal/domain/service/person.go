@@ -2,18 +2,24 @@ package service
 
 import (
 	"context"
+	"errors"
 	"github.com/Kosodaka/enricher-service/internal/domain/dto"
+	domainErr "github.com/Kosodaka/enricher-service/internal/domain/errors"
 	"github.com/Kosodaka/enricher-service/internal/domain/model"
 	"github.com/Kosodaka/enricher-service/internal/domain/ports/enricher"
 	"github.com/Kosodaka/enricher-service/internal/domain/ports/repository"
+	"github.com/Kosodaka/enricher-service/pkg/names"
 	"log/slog"
+	"strings"
+	"time"
 )
 
 type Validator interface {
 	ValidateId(id int) error
 	ValidateDataToAdd(data *dto.AddPersonDTO) error
-	ValidateDataToGet(data *model.Person) error
+	ValidatePersonFilter(data *dto.PersonFilter) error
 	ValidateDataToUpdate(data *model.Person) error
+	ValidateReview(data *dto.ReviewDTO) error
 }
 
 type PersonFullName struct {
@@ -28,29 +34,75 @@ type Service interface {
 	Enricher() enricher.Enricher
 	Logger() slog.Logger
 	Validator() Validator
+	StatusReporter() enricher.StatusReporter
+	QuotaReporter() enricher.QuotaReporter
 	Init(...Option)
 
 	AddPerson(ctx context.Context, data *dto.AddPersonDTO) (int, error)
-	GetPerson(ctx context.Context, id int) (*model.Person, error)
-	GetPersons(ctx context.Context, data *model.Person) ([]model.Person, error)
+	GetPerson(ctx context.Context, id int, includeDeleted bool) (*model.Person, error)
+	GetPersonAsOf(ctx context.Context, id int, asOf time.Time, includeDeleted bool) (*model.Person, error)
+	GetPersonHistory(ctx context.Context, id int) ([]model.PersonHistory, error)
+	GetPersons(ctx context.Context, data *dto.PersonFilter) (*dto.PersonPage, error)
+	SearchPersons(ctx context.Context, query string, limit int, includeDeleted bool) ([]dto.PersonMatch, error)
 	UpdatePerson(ctx context.Context, data *model.Person) error
 	DeletePerson(ctx context.Context, id int) error
+	RestorePerson(ctx context.Context, id int) (*model.Person, error)
+	ProviderStatuses(ctx context.Context) []enricher.ProviderStatus
+	ProviderQuotas(ctx context.Context) []enricher.ProviderQuota
+	AddPersonAsync(ctx context.Context, data *dto.AddPersonDTO) (int, int, error)
+	GetJob(ctx context.Context, id int) (*model.Job, error)
+	ProcessJob(ctx context.Context) (bool, error)
+	ReenrichPerson(ctx context.Context, id int) (*model.Person, error)
+	StartReenrich(ctx context.Context, filter *dto.ReenrichFilter) (*model.ReenrichRun, error)
+	GetReenrich(ctx context.Context, id int) (*model.ReenrichRun, error)
+	CancelReenrich(ctx context.Context, id int) (*model.ReenrichRun, error)
+	GetEnrichmentLog(ctx context.Context, id int) ([]model.EnrichmentLog, error)
+	PruneEnrichmentLog(ctx context.Context, before time.Time) (int64, error)
+	PurgePersons(ctx context.Context, before time.Time) (int64, error)
+	GetReviewQueue(ctx context.Context) ([]model.Person, error)
+	ReviewPerson(ctx context.Context, id int, data *dto.ReviewDTO) (*model.Person, error)
 }
 type Options struct {
-	Repository repository.PersonRepository
-	Enricher   enricher.Enricher
-	Logger     *slog.Logger
-	Validator  Validator
+	Repository     repository.PersonRepository
+	Enricher       enricher.Enricher
+	StatusReporter enricher.StatusReporter
+	QuotaReporter  enricher.QuotaReporter
+	Logger         *slog.Logger
+	Validator      Validator
+	JobRepository  repository.JobRepository
+	// JobAttempts is how many times an asynchronous job is tried before it fails for good.
+	JobAttempts int
+	// PartialEnrichment saves persons whose attributes could only be partly predicted.
+	PartialEnrichment bool
+	// DefaultCountryId localizes the predictions of requests without a country hint.
+	DefaultCountryId string
+	// NameRules answer the attributes they infer with at least NameRulesConfidence,
+	// so the providers are not asked for them.
+	NameRules           enricher.NameRules
+	NameRulesConfidence float64
+	// EnrichmentLogRepository archives the raw provider answers, they are dropped when it is nil.
+	EnrichmentLogRepository repository.EnrichmentLogRepository
+	// ReviewPolicy sends new persons with doubtful predictions to review.
+	ReviewPolicy ReviewPolicy
+	// Reenricher asks the providers past any cache when a stored person is re-enriched,
+	// Enricher is used when it is nil.
+	Reenricher enricher.Enricher
+	// CoalescingReporter counts the enrichment calls served by calls already in flight.
+	CoalescingReporter enricher.CoalescingReporter
 }
 
 type Option func(*Options) error
 
 func NewOptions(opts ...Option) Options {
 	options := Options{
-		Repository: repository.PersonRepository(nil),
-		Enricher:   enricher.Enricher(nil),
-		Logger:     &slog.Logger{},
-		Validator:  Validator(nil),
+		Repository:     repository.PersonRepository(nil),
+		Enricher:       enricher.Enricher(nil),
+		StatusReporter: enricher.StatusReporter(nil),
+		QuotaReporter:  enricher.QuotaReporter(nil),
+		Logger:         &slog.Logger{},
+		Validator:      Validator(nil),
+		JobRepository:  repository.JobRepository(nil),
+		JobAttempts:    1,
 	}
 
 	for _, o := range opts {
@@ -89,6 +141,14 @@ func (s *service) Enricher() enricher.Enricher {
 	return s.opts.Enricher
 }
 
+func (s *service) StatusReporter() enricher.StatusReporter {
+	return s.opts.StatusReporter
+}
+
+func (s *service) QuotaReporter() enricher.QuotaReporter {
+	return s.opts.QuotaReporter
+}
+
 func (s *service) Logger() *slog.Logger {
 	return s.opts.Logger
 }
@@ -111,6 +171,56 @@ func SetEnricher(e enricher.Enricher) Option {
 	}
 }
 
+func SetReenricher(e enricher.Enricher) Option {
+	return func(o *Options) error {
+		o.Reenricher = e
+		return nil
+	}
+}
+
+func SetStatusReporter(r enricher.StatusReporter) Option {
+	return func(o *Options) error {
+		o.StatusReporter = r
+		return nil
+	}
+}
+
+func SetQuotaReporter(r enricher.QuotaReporter) Option {
+	return func(o *Options) error {
+		o.QuotaReporter = r
+		return nil
+	}
+}
+
+func SetCoalescingReporter(r enricher.CoalescingReporter) Option {
+	return func(o *Options) error {
+		o.CoalescingReporter = r
+		return nil
+	}
+}
+
+func SetDefaultCountryId(countryId string) Option {
+	return func(o *Options) error {
+		o.DefaultCountryId = countryId
+		return nil
+	}
+}
+
+func SetNameRules(rules enricher.NameRules, minConfidence float64) Option {
+	return func(o *Options) error {
+		o.NameRules = rules
+		o.NameRulesConfidence = minConfidence
+		return nil
+	}
+}
+
+func SetPartialEnrichment(partial bool) Option {
+	return func(o *Options) error {
+		o.PartialEnrichment = partial
+		return nil
+	}
+}
+
 func SetLogger(l *slog.Logger) Option {
 	return func(o *Options) error {
 		o.Logger = l
@@ -131,19 +241,19 @@ func (s service) AddPerson(ctx context.Context, data *dto.AddPersonDTO) (int, er
 	if err := s.opts.Validator.ValidateDataToAdd(data); err != nil {
 		return 0, err
 	}
-	enrichData, err := s.opts.Enricher.Enrich(ctx, data.Name)
+	personModel := &model.Person{
+		Name:       data.Name,
+		Surname:    data.Surname,
+		Patronymic: data.Patronymic,
+	}
+	latinize(personModel)
+	setManual(personModel, data.Age, data.Gender, data.Nationality)
+	responses, err := s.enrich(ctx, personModel, s.countryId(data.CountryId), false)
 	if err != nil {
+		logger.Debug("failed to enrich person", slog.Any("error", err))
 		return 0, err
 	}
-
-	personModel := &model.Person{
-		Name:        data.Name,
-		Surname:     data.Surname,
-		Patronymic:  data.Patronymic,
-		Age:         enrichData.Age,
-		Gender:      enrichData.Gender,
-		Nationality: enrichData.Nationality,
-	}
+	s.review(personModel)
 
 	id, err := s.opts.Repository.AddPerson(ctx, personModel)
 	if err != nil {
@@ -151,17 +261,200 @@ func (s service) AddPerson(ctx context.Context, data *dto.AddPersonDTO) (int, er
 		return 0, err
 	}
 
+	s.archive(ctx, id, responses)
+
 	logger.Debug("person was successfully added", slog.Any("id", id))
 	return id, nil
 }
 
-func (s service) GetPerson(ctx context.Context, id int) (*model.Person, error) {
+// latinize sets the Latin forms of the person names, the first name one is what the
+// providers are asked about.
+func latinize(person *model.Person) {
+	person.NameLatin = names.Transliterate(person.Name)
+	person.SurnameLatin = names.Transliterate(person.Surname)
+	person.PatronymicLatin = names.Transliterate(person.Patronymic)
+}
+
+// setManual sets the attributes given by a client or a reviewer. They are verified,
+// so the providers are neither asked for them nor allowed to overwrite them.
+func setManual(person *model.Person, age *int, gender *string, nationality *string) {
+	if age != nil {
+		person.Age = age
+		person.AgeCount = 0
+		person.AgeStatus = enricher.StatusOk
+		person.AgeCountryId = ""
+		person.AgeSource = model.SourceManual
+		person.AgeVerified = true
+	}
+	if gender != nil {
+		person.Gender = gender
+		person.GenderProbability = 1
+		person.GenderCount = 0
+		person.GenderStatus = enricher.StatusOk
+		person.GenderCountryId = ""
+		person.GenderSource = model.SourceManual
+		person.GenderVerified = true
+	}
+	if nationality != nil {
+		person.Nationality = nationality
+		person.NationalityProbability = 1
+		person.NationalityCount = 0
+		person.NationalityStatus = enricher.StatusOk
+		person.Nationalities = []model.PersonNationality{{CountryId: *nationality, Probability: 1, Rank: 1}}
+		person.NationalitySource = model.SourceManual
+		person.NationalityVerified = true
+	}
+}
+
+// countryId returns the country hint of a request or the configured default one.
+func (s service) countryId(hint string) string {
+	if hint != "" {
+		return hint
+	}
+	return s.opts.DefaultCountryId
+}
+
+// enrich predicts the attributes of the person by its name and marks it enriched.
+// It returns the raw provider answers even when the person could not be enriched.
+// With fresh set the providers are asked past any cache.
+func (s service) enrich(ctx context.Context, person *model.Person, countryId string, fresh bool) ([]enricher.ProviderResponse, error) {
+	name := person.NameLatin
+	if name == "" {
+		name = names.Transliterate(person.Name)
+	}
+	inference, inferred := s.infer(person)
+	// Manually verified attributes are neither asked for nor overwritten.
+	skip := append(verified(person), inferred...)
+	enrichData := &enricher.EnrichData{
+		AgeStatus:         enricher.StatusNotRequested,
+		GenderStatus:      enricher.StatusNotRequested,
+		NationalityStatus: enricher.StatusNotRequested,
+	}
+	if len(skip) < len(attributes) {
+		var err error
+		e := s.opts.Enricher
+		if fresh && s.opts.Reenricher != nil {
+			e = s.opts.Reenricher
+		}
+		enrichData, err = e.Enrich(ctx, name, countryId, skip...)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, attribute := range inferred {
+		switch attribute {
+		case enricher.AttributeGender:
+			enrichData.Gender = &inference.Gender
+			enrichData.GenderProbability = inference.GenderConfidence
+			enrichData.GenderStatus = enricher.StatusOk
+			enrichData.GenderSource = model.SourceLocalRule
+		case enricher.AttributeNationality:
+			enrichData.Nationality = &inference.Nationality
+			enrichData.NationalityProbability = inference.NationalityConfidence
+			enrichData.NationalityStatus = enricher.StatusOk
+			enrichData.NationalitySource = model.SourceLocalRule
+			enrichData.Nationalities = []enricher.Country{{CountryId: inference.Nationality, Probability: inference.NationalityConfidence}}
+		}
+	}
+	// A spent quota is refused even in partial mode, so the person is not saved half empty
+	// when it could be enriched fully once the quota is back.
+	if errors.Is(enrichData.Err, domainErr.ErrProviderRateLimited) {
+		return enrichData.Responses, enrichData.Err
+	}
+	if !complete(enrichData, person) && !s.opts.PartialEnrichment {
+		if enrichData.Err != nil {
+			return enrichData.Responses, enrichData.Err
+		}
+		return enrichData.Responses, domainErr.ErrNameUnknown
+	}
+
+	// A re-enriched person keeps the attributes the providers have no answer for anymore.
+	if !person.AgeVerified && (enrichData.Age != nil || person.Age == nil) {
+		person.Age = enrichData.Age
+		person.AgeCount = enrichData.AgeCount
+		person.AgeStatus = enrichData.AgeStatus
+		person.AgeCountryId = enrichData.AgeCountryId
+		person.AgeSource = enrichData.AgeSource
+	}
+	if !person.GenderVerified && (enrichData.Gender != nil || person.Gender == nil) {
+		person.Gender = enrichData.Gender
+		person.GenderProbability = enrichData.GenderProbability
+		person.GenderCount = enrichData.GenderCount
+		person.GenderStatus = enrichData.GenderStatus
+		person.GenderCountryId = enrichData.GenderCountryId
+		person.GenderSource = enrichData.GenderSource
+	}
+	if !person.NationalityVerified && (enrichData.Nationality != nil || person.Nationality == nil) {
+		person.Nationality = enrichData.Nationality
+		person.NationalityProbability = enrichData.NationalityProbability
+		person.NationalityCount = enrichData.NationalityCount
+		person.NationalityStatus = enrichData.NationalityStatus
+		person.NationalitySource = enrichData.NationalitySource
+		person.Nationalities = nil
+		for i, n := range enrichData.Nationalities {
+			person.Nationalities = append(person.Nationalities, model.PersonNationality{
+				CountryId:   n.CountryId,
+				Probability: n.Probability,
+				Rank:        i + 1,
+			})
+		}
+	}
+	// A person under review stays there until a reviewer is done with it.
+	if person.Status != model.PersonStatusNeedsReview {
+		person.Status = model.PersonStatusEnriched
+	}
+	return enrichData.Responses, nil
+}
+
+// attributes are all the attributes the providers predict.
+var attributes = []string{enricher.AttributeAge, enricher.AttributeGender, enricher.AttributeNationality}
+
+// verified returns the attributes set by hand.
+func verified(person *model.Person) []string {
+	var attributes []string
+	if person.AgeVerified {
+		attributes = append(attributes, enricher.AttributeAge)
+	}
+	if person.GenderVerified {
+		attributes = append(attributes, enricher.AttributeGender)
+	}
+	if person.NationalityVerified {
+		attributes = append(attributes, enricher.AttributeNationality)
+	}
+	return attributes
+}
+
+// complete reports whether every attribute was either predicted or verified.
+func complete(data *enricher.EnrichData, person *model.Person) bool {
+	return (data.Age != nil || person.AgeVerified) &&
+		(data.Gender != nil || person.GenderVerified) &&
+		(data.Nationality != nil || person.NationalityVerified)
+}
+
+// infer applies the name rules and returns the attributes they are confident enough
+// about to skip the providers.
+func (s service) infer(person *model.Person) (enricher.Inference, []string) {
+	if s.opts.NameRules == nil {
+		return enricher.Inference{}, nil
+	}
+	inference := s.opts.NameRules.Infer(person.Surname, person.Patronymic)
+	var skip []string
+	if inference.Gender != "" && inference.GenderConfidence >= s.opts.NameRulesConfidence && !person.GenderVerified {
+		skip = append(skip, enricher.AttributeGender)
+	}
+	if inference.Nationality != "" && inference.NationalityConfidence >= s.opts.NameRulesConfidence && !person.NationalityVerified {
+		skip = append(skip, enricher.AttributeNationality)
+	}
+	return inference, skip
+}
+
+func (s service) GetPerson(ctx context.Context, id int, includeDeleted bool) (*model.Person, error) {
 	op := "service.GetPerson"
 	logger := s.opts.Logger.With("operation", op)
 	if err := s.opts.Validator.ValidateId(id); err != nil {
 		return nil, err
 	}
-	person, err := s.opts.Repository.GetPerson(ctx, id)
+	person, err := s.opts.Repository.GetPerson(ctx, id, includeDeleted)
 	if err != nil {
 		return nil, err
 	}
@@ -169,19 +462,42 @@ func (s service) GetPerson(ctx context.Context, id int) (*model.Person, error) {
 	return person, nil
 }
 
-func (s service) GetPersons(ctx context.Context, data *model.Person) ([]model.Person, error) {
+func (s service) GetPersons(ctx context.Context, data *dto.PersonFilter) (*dto.PersonPage, error) {
 	op := "service.GetPersons"
 	logger := s.opts.Logger.With("operation", op)
-	if err := s.opts.Validator.ValidateDataToGet(data); err != nil {
+	if err := s.opts.Validator.ValidatePersonFilter(data); err != nil {
 		return nil, err
 	}
-	person, err := s.opts.Repository.GetPersons(ctx, data)
+	if data.Limit == 0 {
+		data.Limit = dto.DefaultPersonLimit
+	}
+	page, err := s.opts.Repository.GetPersons(ctx, data)
 	if err != nil {
 		logger.Debug("failed to get persons")
 		return nil, err
 	}
-	logger.Debug("persons was successfully got")
-	return person, nil
+	logger.Debug("persons was successfully got", slog.Int("count", len(page.Persons)))
+	return page, nil
+}
+
+// SearchPersons finds persons by their approximate full name, tolerating typos.
+func (s service) SearchPersons(ctx context.Context, query string, limit int, includeDeleted bool) ([]dto.PersonMatch, error) {
+	op := "service.SearchPersons"
+	logger := s.opts.Logger.With("operation", op)
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, domainErr.EmptyField
+	}
+	if limit == 0 {
+		limit = dto.DefaultSearchLimit
+	}
+	matches, err := s.opts.Repository.SearchPersons(ctx, query, limit, includeDeleted)
+	if err != nil {
+		logger.Debug("failed to search persons", slog.String("error", err.Error()))
+		return nil, err
+	}
+	logger.Debug("persons was successfully searched", slog.Int("count", len(matches)))
+	return matches, nil
 }
 
 func (s service) DeletePerson(ctx context.Context, id int) error {
@@ -199,12 +515,41 @@ func (s service) DeletePerson(ctx context.Context, id int) error {
 	return err
 }
 
+// RestorePerson brings back a deleted person that was not purged yet.
+func (s service) RestorePerson(ctx context.Context, id int) (*model.Person, error) {
+	op := "service.RestorePerson"
+	logger := s.opts.Logger.With("operation", op)
+	if err := s.opts.Validator.ValidateId(id); err != nil {
+		return nil, err
+	}
+	if err := s.opts.Repository.RestorePerson(ctx, id); err != nil {
+		logger.Debug("fail to restore person", slog.Int("id", id), slog.String("error", err.Error()))
+		return nil, err
+	}
+	logger.Debug("person was successfully restored", slog.Int("id", id))
+	return s.opts.Repository.GetPerson(ctx, id, false)
+}
+
+// PurgePersons removes the persons deleted before the given time for good.
+func (s service) PurgePersons(ctx context.Context, before time.Time) (int64, error) {
+	op := "service.PurgePersons"
+	logger := s.opts.Logger.With("operation", op)
+	purged, err := s.opts.Repository.PurgePersons(ctx, before)
+	if err != nil {
+		logger.Debug("failed to purge persons", slog.Any("error", err))
+		return 0, err
+	}
+	logger.Debug("deleted persons were successfully purged", slog.Int64("purged", purged))
+	return purged, nil
+}
+
 func (s service) UpdatePerson(ctx context.Context, data *model.Person) error {
 	op := "service.DeletePerson"
 	logger := s.opts.Logger.With("operation", op)
 	if err := s.opts.Validator.ValidateDataToUpdate(data); err != nil {
 		return err
 	}
+	latinize(data)
 	err := s.opts.Repository.UpdatePerson(ctx, data)
 	if err != nil {
 		logger.Debug("fail to update person", slog.Any("error", err.Error()))
@@ -213,3 +558,24 @@ func (s service) UpdatePerson(ctx context.Context, data *model.Person) error {
 	}
 	return err
 }
+
+func (s service) ProviderStatuses(ctx context.Context) []enricher.ProviderStatus {
+	if s.opts.StatusReporter == nil {
+		return []enricher.ProviderStatus{}
+	}
+	return s.opts.StatusReporter.ProviderStatuses()
+}
+
+func (s service) ProviderQuotas(ctx context.Context) []enricher.ProviderQuota {
+	if s.opts.QuotaReporter == nil {
+		return []enricher.ProviderQuota{}
+	}
+	return s.opts.QuotaReporter.ProviderQuotas()
+}
+
+func (s service) CoalescingStats(ctx context.Context) enricher.CoalescingStats {
+	if s.opts.CoalescingReporter == nil {
+		return enricher.CoalescingStats{}
+	}
+	return s.opts.CoalescingReporter.CoalescingStats()
+}
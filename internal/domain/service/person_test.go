@@ -10,6 +10,7 @@ import (
 	mock_enricher "github.com/Kosodaka/enricher-service/pkg/mocks/api/enricher"
 	mock_repository "github.com/Kosodaka/enricher-service/pkg/mocks/api/repository"
 	mock_service "github.com/Kosodaka/enricher-service/pkg/mocks/api/service"
+	"github.com/Kosodaka/enricher-service/pkg/ptr"
 	"github.com/Kosodaka/enricher-service/pkg/validator"
 	validation "github.com/go-ozzo/ozzo-validation"
 	"github.com/golang/mock/gomock"
@@ -42,24 +43,78 @@ func TestService_AddPerson(t *testing.T) {
 				Surname: "Dementiev",
 			},
 			enrichData: &enricher.EnrichData{
-				Age:         60,
-				Gender:      "male",
-				Nationality: "RU",
+				Age:                    ptr.To(60),
+				AgeCount:               1200,
+				AgeStatus:              enricher.StatusOk,
+				Gender:                 ptr.To("male"),
+				GenderProbability:      0.99,
+				GenderCount:            3400,
+				GenderStatus:           enricher.StatusOk,
+				Nationality:            ptr.To("RU"),
+				NationalityProbability: 0.45,
+				NationalityCount:       900,
+				NationalityStatus:      enricher.StatusOk,
 			},
 			preparation: func(d *dependencies, data *dto.AddPersonDTO, enrichData *enricher.EnrichData, ctx context.Context, err error) {
 				person := &model.Person{
-					Name:        data.Name,
-					Surname:     data.Surname,
-					Patronymic:  data.Patronymic,
-					Age:         enrichData.Age,
-					Gender:      enrichData.Gender,
-					Nationality: enrichData.Nationality,
+					Name:                   data.Name,
+					Surname:                data.Surname,
+					Patronymic:             data.Patronymic,
+					NameLatin:              data.Name,
+					SurnameLatin:           data.Surname,
+					PatronymicLatin:        data.Patronymic,
+					Age:                    enrichData.Age,
+					AgeCount:               enrichData.AgeCount,
+					AgeStatus:              enrichData.AgeStatus,
+					Gender:                 enrichData.Gender,
+					GenderProbability:      enrichData.GenderProbability,
+					GenderCount:            enrichData.GenderCount,
+					GenderStatus:           enrichData.GenderStatus,
+					Nationality:            enrichData.Nationality,
+					NationalityProbability: enrichData.NationalityProbability,
+					NationalityCount:       enrichData.NationalityCount,
+					NationalityStatus:      enrichData.NationalityStatus,
+					Status:                 model.PersonStatusEnriched,
 				}
 				d.repository.EXPECT().AddPerson(ctx, person).Return(int(1), nil)
-				d.enricher.EXPECT().Enrich(ctx, data.Name).Return(enrichData, nil)
+				d.enricher.EXPECT().Enrich(ctx, data.Name, "").Return(enrichData, nil)
 			},
 			output: 1,
 			err:    nil,
+		}, {
+			name:     "cyrillic name is sent transliterated",
+			deadline: time.Second * 10,
+			input: &dto.AddPersonDTO{
+				Name:    "Алексей",
+				Surname: "Щукин",
+			},
+			enrichData: &enricher.EnrichData{
+				Age:               ptr.To(35),
+				AgeStatus:         enricher.StatusOk,
+				Gender:            ptr.To("male"),
+				GenderStatus:      enricher.StatusOk,
+				Nationality:       ptr.To("RU"),
+				NationalityStatus: enricher.StatusOk,
+			},
+			preparation: func(d *dependencies, data *dto.AddPersonDTO, enrichData *enricher.EnrichData, ctx context.Context, err error) {
+				person := &model.Person{
+					Name:              data.Name,
+					Surname:           data.Surname,
+					NameLatin:         "Aleksey",
+					SurnameLatin:      "Shchukin",
+					Age:               enrichData.Age,
+					AgeStatus:         enrichData.AgeStatus,
+					Gender:            enrichData.Gender,
+					GenderStatus:      enrichData.GenderStatus,
+					Nationality:       enrichData.Nationality,
+					NationalityStatus: enrichData.NationalityStatus,
+					Status:            model.PersonStatusEnriched,
+				}
+				d.repository.EXPECT().AddPerson(ctx, person).Return(int(2), nil)
+				d.enricher.EXPECT().Enrich(ctx, "Aleksey", "").Return(enrichData, nil)
+			},
+			output: 2,
+			err:    nil,
 		}, {
 			name:     "invalid input data",
 			deadline: time.Second * 10,
@@ -71,6 +126,26 @@ func TestService_AddPerson(t *testing.T) {
 			preparation: nil,
 			output:      0,
 			err:         validation.Errors{"name": domainErr.InvalidData},
+		}, {
+			name:     "unknown nationality without partial enrichment",
+			deadline: time.Second * 10,
+			input: &dto.AddPersonDTO{
+				Name:    "Zzyzx",
+				Surname: "Ivanov",
+			},
+			enrichData: &enricher.EnrichData{
+				Age:               ptr.To(40),
+				AgeStatus:         enricher.StatusOk,
+				Gender:            ptr.To("male"),
+				GenderStatus:      enricher.StatusOk,
+				NationalityStatus: enricher.StatusUnknown,
+				Err:               domainErr.ErrNameUnknown,
+			},
+			preparation: func(d *dependencies, data *dto.AddPersonDTO, enrichData *enricher.EnrichData, ctx context.Context, err error) {
+				d.enricher.EXPECT().Enrich(ctx, data.Name, "").Return(enrichData, nil)
+			},
+			output: 0,
+			err:    domainErr.ErrNameUnknown,
 		},
 	}
 	ctrl := gomock.NewController(t)
@@ -103,6 +178,60 @@ func TestService_AddPerson(t *testing.T) {
 		})
 	}
 }
+func TestService_AddPersonNameRules(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repository := mock_repository.NewMockPersonRepository(ctrl)
+	enrich := mock_enricher.NewMockEnricher(ctrl)
+	rules := mock_enricher.NewMockNameRules(ctrl)
+	svc := NewService()
+	svc.Init(SetLogger(logger.SetupLogger("test")), SetValidator(validator.NewValidator()),
+		SetRepository(repository), SetEnricher(enrich), SetNameRules(rules, 0.9))
+
+	ctx := context.Background()
+	rules.EXPECT().Infer("Ivanova", "Petrovna").Return(enricher.Inference{
+		Gender:                "female",
+		GenderConfidence:      0.99,
+		Nationality:           "RU",
+		NationalityConfidence: 0.6,
+	})
+	// The confident gender is not asked for, the nationality still is.
+	enrich.EXPECT().Enrich(ctx, "Sasha", "", enricher.AttributeGender).Return(&enricher.EnrichData{
+		Age:                    ptr.To(30),
+		AgeStatus:              enricher.StatusOk,
+		AgeSource:              "agify",
+		GenderStatus:           enricher.StatusNotRequested,
+		Nationality:            ptr.To("UA"),
+		NationalityProbability: 0.4,
+		NationalityStatus:      enricher.StatusOk,
+		NationalitySource:      "nationalize",
+	}, nil)
+	repository.EXPECT().AddPerson(ctx, &model.Person{
+		Name:                   "Sasha",
+		Surname:                "Ivanova",
+		Patronymic:             "Petrovna",
+		NameLatin:              "Sasha",
+		SurnameLatin:           "Ivanova",
+		PatronymicLatin:        "Petrovna",
+		Age:                    ptr.To(30),
+		AgeStatus:              enricher.StatusOk,
+		AgeSource:              "agify",
+		Gender:                 ptr.To("female"),
+		GenderProbability:      0.99,
+		GenderStatus:           enricher.StatusOk,
+		GenderSource:           model.SourceLocalRule,
+		Nationality:            ptr.To("UA"),
+		NationalityProbability: 0.4,
+		NationalityStatus:      enricher.StatusOk,
+		NationalitySource:      "nationalize",
+		Status:                 model.PersonStatusEnriched,
+	}).Return(1, nil)
+
+	id, err := svc.AddPerson(ctx, &dto.AddPersonDTO{Name: "Sasha", Surname: "Ivanova", Patronymic: "Petrovna"})
+	if id != 1 || err != nil {
+		t.Errorf("got %d, %v, want 1, nil", id, err)
+	}
+}
+
 func TestService_GetPerson(t *testing.T) {
 	cases := []struct {
 		name        string
@@ -122,20 +251,20 @@ func TestService_GetPerson(t *testing.T) {
 					Name:        "Nikolay",
 					Surname:     "Chernyaev",
 					Patronymic:  "",
-					Age:         21,
-					Gender:      "male",
-					Nationality: "RU",
+					Age:         ptr.To(21),
+					Gender:      ptr.To("male"),
+					Nationality: ptr.To("RU"),
 				}
-				d.repository.EXPECT().GetPerson(ctx, id).Return(person, nil)
+				d.repository.EXPECT().GetPerson(ctx, id, false).Return(person, nil)
 			},
 			output: &model.Person{
 				Id:          1,
 				Name:        "Nikolay",
 				Surname:     "Chernyaev",
 				Patronymic:  "",
-				Age:         21,
-				Gender:      "male",
-				Nationality: "RU",
+				Age:         ptr.To(21),
+				Gender:      ptr.To("male"),
+				Nationality: ptr.To("RU"),
 			},
 			err: nil,
 		},
@@ -167,7 +296,7 @@ func TestService_GetPerson(t *testing.T) {
 			}
 			svc.opts.Repository = dependencies.repository
 			svc.opts.Enricher = dependencies.enricher
-			result, err := svc.GetPerson(ctx, testCases.input)
+			result, err := svc.GetPerson(ctx, testCases.input, false)
 
 			if !reflect.DeepEqual(result, testCases.output) {
 				t.Errorf("got %v, want %v", result, testCases.output)
@@ -179,3 +308,173 @@ func TestService_GetPerson(t *testing.T) {
 
 	}
 }
+
+func TestService_AddPersonManualAttributes(t *testing.T) {
+	cases := []struct {
+		name        string
+		input       *dto.AddPersonDTO
+		preparation func(e *mock_enricher.MockEnricher)
+		want        func(p *model.Person) bool
+	}{
+		{
+			name:  "only missing attributes are asked for",
+			input: &dto.AddPersonDTO{Name: "Sasha", Surname: "Lee", Gender: ptr.To("male")},
+			preparation: func(e *mock_enricher.MockEnricher) {
+				e.EXPECT().Enrich(gomock.Any(), "Sasha", "", enricher.AttributeGender).Return(&enricher.EnrichData{
+					Age:               ptr.To(30),
+					AgeStatus:         enricher.StatusOk,
+					AgeSource:         "agify",
+					GenderStatus:      enricher.StatusNotRequested,
+					Nationality:       ptr.To("KR"),
+					NationalityStatus: enricher.StatusOk,
+					NationalitySource: "nationalize",
+				}, nil)
+			},
+			want: func(p *model.Person) bool {
+				return *p.Gender == "male" && p.GenderSource == model.SourceManual && p.GenderVerified &&
+					*p.Age == 30 && p.AgeSource == "agify" && !p.AgeVerified &&
+					*p.Nationality == "KR" && p.NationalitySource == "nationalize"
+			},
+		}, {
+			name: "known person skips the providers",
+			input: &dto.AddPersonDTO{Name: "Sasha", Surname: "Lee",
+				Age: ptr.To(41), Gender: ptr.To("female"), Nationality: ptr.To("KR")},
+			preparation: func(e *mock_enricher.MockEnricher) {},
+			want: func(p *model.Person) bool {
+				return *p.Age == 41 && *p.Gender == "female" && *p.Nationality == "KR" &&
+					p.AgeSource == model.SourceManual && p.GenderSource == model.SourceManual &&
+					p.NationalitySource == model.SourceManual && p.Status == model.PersonStatusEnriched
+			},
+		},
+	}
+	for _, testCases := range cases {
+		t.Run(testCases.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			repository := mock_repository.NewMockPersonRepository(ctrl)
+			enrich := mock_enricher.NewMockEnricher(ctrl)
+			svc := NewService()
+			svc.Init(SetLogger(logger.SetupLogger("test")), SetValidator(validator.NewValidator()),
+				SetRepository(repository), SetEnricher(enrich))
+
+			testCases.preparation(enrich)
+			repository.EXPECT().AddPerson(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, person *model.Person) (int, error) {
+				if !testCases.want(person) {
+					t.Errorf("got unexpected person %+v", person)
+				}
+				return 2, nil
+			})
+
+			if _, err := svc.AddPerson(context.Background(), testCases.input); err != nil {
+				t.Fatalf("got error %v", err)
+			}
+		})
+	}
+}
+
+func TestService_SearchPersons(t *testing.T) {
+	match := dto.PersonMatch{Person: model.Person{Id: 1, Name: "Ivan", Surname: "Dementiev"}, Score: 0.67}
+	cases := []struct {
+		name        string
+		query       string
+		limit       int
+		preparation func(d *dependencies)
+		output      []dto.PersonMatch
+		err         error
+	}{
+		{
+			name:  "default limit",
+			query: " Dementev ",
+			preparation: func(d *dependencies) {
+				d.repository.EXPECT().SearchPersons(gomock.Any(), "Dementev", dto.DefaultSearchLimit, false).Return([]dto.PersonMatch{match}, nil)
+			},
+			output: []dto.PersonMatch{match},
+		},
+		{
+			name:  "given limit",
+			query: "Dementev",
+			limit: 5,
+			preparation: func(d *dependencies) {
+				d.repository.EXPECT().SearchPersons(gomock.Any(), "Dementev", 5, false).Return([]dto.PersonMatch{}, nil)
+			},
+			output: []dto.PersonMatch{},
+		},
+		{
+			name:  "blank query",
+			query: "  ",
+			err:   domainErr.EmptyField,
+		},
+	}
+
+	for _, testCases := range cases {
+		t.Run(testCases.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			dependencies := &dependencies{repository: mock_repository.NewMockPersonRepository(ctrl)}
+			if testCases.preparation != nil {
+				testCases.preparation(dependencies)
+			}
+			svc := NewService()
+			svc.Init(SetLogger(logger.SetupLogger("test")), SetValidator(validator.NewValidator()), SetRepository(dependencies.repository))
+
+			result, err := svc.SearchPersons(context.Background(), testCases.query, testCases.limit, false)
+			if !reflect.DeepEqual(result, testCases.output) {
+				t.Errorf("got %v, want %v", result, testCases.output)
+			}
+			if err != testCases.err {
+				t.Errorf("got %v, want %v", err, testCases.err)
+			}
+		})
+	}
+}
+
+func TestService_RestorePerson(t *testing.T) {
+	cases := []struct {
+		name        string
+		id          int
+		preparation func(d *dependencies)
+		output      *model.Person
+		err         error
+	}{
+		{
+			name: "deleted person",
+			id:   3,
+			preparation: func(d *dependencies) {
+				d.repository.EXPECT().RestorePerson(gomock.Any(), 3).Return(nil)
+				d.repository.EXPECT().GetPerson(gomock.Any(), 3, false).Return(&model.Person{Id: 3, Name: "Olga"}, nil)
+			},
+			output: &model.Person{Id: 3, Name: "Olga"},
+		},
+		{
+			name: "person not deleted",
+			id:   4,
+			preparation: func(d *dependencies) {
+				d.repository.EXPECT().RestorePerson(gomock.Any(), 4).Return(domainErr.ErrPersonNotFound)
+			},
+			err: domainErr.ErrPersonNotFound,
+		},
+		{
+			name: "invalid id",
+			id:   0,
+			err:  domainErr.InvalidId,
+		},
+	}
+
+	for _, testCases := range cases {
+		t.Run(testCases.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			dependencies := &dependencies{repository: mock_repository.NewMockPersonRepository(ctrl)}
+			if testCases.preparation != nil {
+				testCases.preparation(dependencies)
+			}
+			svc := NewService()
+			svc.Init(SetLogger(logger.SetupLogger("test")), SetValidator(validator.NewValidator()), SetRepository(dependencies.repository))
+
+			result, err := svc.RestorePerson(context.Background(), testCases.id)
+			if !reflect.DeepEqual(result, testCases.output) {
+				t.Errorf("got %v, want %v", result, testCases.output)
+			}
+			if !reflect.DeepEqual(err, testCases.err) {
+				t.Errorf("got %v, want %v", err, testCases.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"github.com/Kosodaka/enricher-service/internal/domain/dto"
+	"github.com/Kosodaka/enricher-service/internal/domain/model"
+	"log/slog"
+)
+
+// ReenrichPerson asks the providers again for a stored person and saves the new predictions.
+func (s service) ReenrichPerson(ctx context.Context, id int) (*model.Person, error) {
+	op := "service.ReenrichPerson"
+	logger := s.opts.Logger.With("operation", op, slog.Int("id", id))
+	if err := s.opts.Validator.ValidateId(id); err != nil {
+		return nil, err
+	}
+	person, err := s.opts.Repository.GetPerson(ctx, id, false)
+	if err != nil {
+		return nil, err
+	}
+	responses, err := s.enrich(ctx, person, s.countryId(person.AgeCountryId), true)
+	s.archive(ctx, id, responses)
+	if err != nil {
+		logger.Debug("failed to re-enrich person", slog.Any("error", err))
+		return nil, err
+	}
+	if err := s.opts.Repository.UpdateEnrichment(ctx, person); err != nil {
+		logger.Debug("failed to save re-enriched person", slog.Any("error", err))
+		return nil, err
+	}
+	logger.Debug("person was successfully re-enriched")
+	return person, nil
+}
+
+// StartReenrich queues the re-enrichment of every person matching the filter for the workers.
+func (s service) StartReenrich(ctx context.Context, filter *dto.ReenrichFilter) (*model.ReenrichRun, error) {
+	op := "service.StartReenrich"
+	logger := s.opts.Logger.With("operation", op)
+	if err := s.opts.Validator.ValidatePersonFilter(&filter.PersonFilter); err != nil {
+		return nil, err
+	}
+	run, err := s.opts.JobRepository.AddReenrichRun(ctx, filter)
+	if err != nil {
+		logger.Debug("failed to start re-enrichment", slog.Any("error", err))
+		return nil, err
+	}
+	logger.Debug("re-enrichment was successfully started", slog.Int64("run_id", run.Id), slog.Int("total", run.Total))
+	return run, nil
+}
+
+func (s service) GetReenrich(ctx context.Context, id int) (*model.ReenrichRun, error) {
+	if err := s.opts.Validator.ValidateId(id); err != nil {
+		return nil, err
+	}
+	return s.opts.JobRepository.GetReenrichRun(ctx, id)
+}
+
+func (s service) CancelReenrich(ctx context.Context, id int) (*model.ReenrichRun, error) {
+	op := "service.CancelReenrich"
+	logger := s.opts.Logger.With("operation", op, slog.Int("run_id", id))
+	if err := s.opts.Validator.ValidateId(id); err != nil {
+		return nil, err
+	}
+	run, err := s.opts.JobRepository.CancelReenrichRun(ctx, id)
+	if err != nil {
+		logger.Debug("failed to cancel re-enrichment", slog.Any("error", err))
+		return nil, err
+	}
+	logger.Debug("re-enrichment was cancelled", slog.Int("cancelled", run.Cancelled))
+	return run, nil
+}
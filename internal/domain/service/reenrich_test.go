@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/Kosodaka/enricher-service/internal/domain/dto"
+	domainErr "github.com/Kosodaka/enricher-service/internal/domain/errors"
+	"github.com/Kosodaka/enricher-service/internal/domain/model"
+	"github.com/Kosodaka/enricher-service/internal/domain/ports/enricher"
+	"github.com/Kosodaka/enricher-service/internal/domain/ports/repository"
+	"github.com/Kosodaka/enricher-service/pkg/logger"
+	mock_enricher "github.com/Kosodaka/enricher-service/pkg/mocks/api/enricher"
+	mock_repository "github.com/Kosodaka/enricher-service/pkg/mocks/api/repository"
+	"github.com/Kosodaka/enricher-service/pkg/ptr"
+	"github.com/Kosodaka/enricher-service/pkg/validator"
+	"github.com/golang/mock/gomock"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestService_ReenrichPerson(t *testing.T) {
+	stored := func() *model.Person {
+		return &model.Person{
+			Id:                7,
+			Name:              "Olga",
+			NameLatin:         "Olga",
+			Age:               ptr.To(30),
+			AgeStatus:         enricher.StatusOk,
+			AgeCountryId:      "KZ",
+			Gender:            ptr.To("female"),
+			GenderStatus:      enricher.StatusOk,
+			Nationality:       ptr.To("UA"),
+			NationalityStatus: enricher.StatusOk,
+			Nationalities:     []model.PersonNationality{{CountryId: "UA", Probability: 0.7, Rank: 1}},
+			Status:            model.PersonStatusEnriched,
+		}
+	}
+	cases := []struct {
+		name       string
+		partial    bool
+		enrichData *enricher.EnrichData
+		want       func() *model.Person
+		err        error
+	}{
+		{
+			name:    "predictions are refreshed",
+			partial: true,
+			enrichData: &enricher.EnrichData{
+				Age:               ptr.To(31),
+				AgeStatus:         enricher.StatusOk,
+				AgeCountryId:      "KZ",
+				Gender:            ptr.To("female"),
+				GenderStatus:      enricher.StatusOk,
+				Nationality:       ptr.To("KZ"),
+				NationalityStatus: enricher.StatusOk,
+				Nationalities:     []enricher.Country{{CountryId: "KZ", Probability: 0.6}},
+			},
+			want: func() *model.Person {
+				person := stored()
+				person.Age = ptr.To(31)
+				person.Nationality = ptr.To("KZ")
+				person.Nationalities = []model.PersonNationality{{CountryId: "KZ", Probability: 0.6, Rank: 1}}
+				return person
+			},
+		}, {
+			name:    "failed attributes keep their values",
+			partial: true,
+			enrichData: &enricher.EnrichData{
+				Age:               ptr.To(31),
+				AgeStatus:         enricher.StatusOk,
+				AgeCountryId:      "KZ",
+				GenderStatus:      enricher.StatusProviderError,
+				NationalityStatus: enricher.StatusUnknown,
+				Err:               domainErr.ErrProviderUnavailable,
+			},
+			want: func() *model.Person {
+				person := stored()
+				person.Age = ptr.To(31)
+				return person
+			},
+		}, {
+			name: "incomplete predictions are refused without partial enrichment",
+			enrichData: &enricher.EnrichData{
+				Age:          ptr.To(31),
+				AgeStatus:    enricher.StatusOk,
+				GenderStatus: enricher.StatusProviderError,
+				Err:          domainErr.ErrProviderUnavailable,
+			},
+			err: domainErr.ErrProviderUnavailable,
+		},
+	}
+	for _, testCases := range cases {
+		t.Run(testCases.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			persons := mock_repository.NewMockPersonRepository(ctrl)
+			// The cached enricher must not be asked, the predictions could be served stale.
+			cached := mock_enricher.NewMockEnricher(ctrl)
+			e := mock_enricher.NewMockEnricher(ctrl)
+			svc := NewService()
+			svc.Init(SetLogger(logger.SetupLogger("test")), SetValidator(validator.NewValidator()),
+				SetRepository(persons), SetEnricher(cached), SetReenricher(e), SetPartialEnrichment(testCases.partial))
+
+			persons.EXPECT().GetPerson(gomock.Any(), 7, false).Return(stored(), nil)
+			// The person is re-enriched localized as before.
+			e.EXPECT().Enrich(gomock.Any(), "Olga", "KZ").Return(testCases.enrichData, nil)
+			if testCases.want != nil {
+				persons.EXPECT().UpdateEnrichment(gomock.Any(), testCases.want()).Return(nil)
+			}
+
+			person, err := svc.ReenrichPerson(context.Background(), 7)
+			if !errors.Is(err, testCases.err) {
+				t.Fatalf("got %v, want %v", err, testCases.err)
+			}
+			if testCases.want != nil && !reflect.DeepEqual(person, testCases.want()) {
+				t.Errorf("got %+v, want %+v", person, testCases.want())
+			}
+		})
+	}
+}
+
+func TestService_ReenrichPersonChangedMeanwhile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	persons := mock_repository.NewMockPersonRepository(ctrl)
+	e := mock_enricher.NewMockEnricher(ctrl)
+	svc := NewService()
+	svc.Init(SetLogger(logger.SetupLogger("test")), SetValidator(validator.NewValidator()),
+		SetRepository(persons), SetEnricher(e), SetPartialEnrichment(true))
+
+	conflict := &repository.ErrVersionConflict{Id: 7, Expected: 3, Current: 4}
+	persons.EXPECT().GetPerson(gomock.Any(), 7, false).Return(&model.Person{Id: 7, Name: "Olga", NameLatin: "Olga", Version: 3}, nil)
+	e.EXPECT().Enrich(gomock.Any(), "Olga", "").Return(&enricher.EnrichData{
+		Gender:       ptr.To("male"),
+		GenderStatus: enricher.StatusOk,
+	}, nil)
+	// A PATCH setting the gender by hand commits while the providers are asked, so the
+	// predictions of version 3 must not be written over version 4.
+	persons.EXPECT().UpdateEnrichment(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, person *model.Person) error {
+		if person.Version != 3 {
+			t.Errorf("got version %d, want the version read 3", person.Version)
+		}
+		return conflict
+	})
+
+	if _, err := svc.ReenrichPerson(context.Background(), 7); !errors.Is(err, conflict) {
+		t.Fatalf("got %v, want %v", err, conflict)
+	}
+}
+
+func TestService_StartReenrich(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	jobs := mock_repository.NewMockJobRepository(ctrl)
+	svc := NewService()
+	svc.Init(SetLogger(logger.SetupLogger("test")), SetValidator(validator.NewValidator()), SetJobRepository(jobs))
+
+	before := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	filter := &dto.ReenrichFilter{EnrichedBefore: &before}
+	filter.Nationality = ptr.To("RU")
+	run := &model.ReenrichRun{Id: 3, Status: model.RunStatusRunning, Total: 2, Queued: 2}
+	jobs.EXPECT().AddReenrichRun(gomock.Any(), filter).Return(run, nil)
+
+	got, err := svc.StartReenrich(context.Background(), filter)
+	if err != nil || got != run {
+		t.Errorf("got %+v, %v, want %+v", got, err, run)
+	}
+
+	filter.Nationality = ptr.To("Russia")
+	if _, err := svc.StartReenrich(context.Background(), filter); err == nil {
+		t.Error("got no error for an invalid filter")
+	}
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"github.com/Kosodaka/enricher-service/internal/domain/dto"
+	domainErr "github.com/Kosodaka/enricher-service/internal/domain/errors"
+	"github.com/Kosodaka/enricher-service/internal/domain/model"
+	"log/slog"
+	"strings"
+)
+
+// ReviewPolicy returns the reasons to have a person reviewed, none when its predictions are trusted.
+type ReviewPolicy interface {
+	Review(person *model.Person) []string
+}
+
+func SetReviewPolicy(p ReviewPolicy) Option {
+	return func(o *Options) error {
+		o.ReviewPolicy = p
+		return nil
+	}
+}
+
+// review sends an enriched person to review when the policy doubts its predictions.
+func (s service) review(person *model.Person) {
+	if s.opts.ReviewPolicy == nil || person.Status != model.PersonStatusEnriched {
+		return
+	}
+	if reasons := s.opts.ReviewPolicy.Review(person); len(reasons) > 0 {
+		person.Status = model.PersonStatusNeedsReview
+		person.ReviewReason = strings.Join(reasons, "; ")
+	}
+}
+
+func (s service) GetReviewQueue(ctx context.Context) ([]model.Person, error) {
+	return s.opts.Repository.GetPersonsToReview(ctx)
+}
+
+// ReviewPerson takes a person out of the review queue. The corrected attributes replace
+// the predicted ones and are never overwritten by re-enrichment.
+func (s service) ReviewPerson(ctx context.Context, id int, data *dto.ReviewDTO) (*model.Person, error) {
+	op := "service.ReviewPerson"
+	logger := s.opts.Logger.With("operation", op, slog.Int("id", id))
+	if err := s.opts.Validator.ValidateId(id); err != nil {
+		return nil, err
+	}
+	if err := s.opts.Validator.ValidateReview(data); err != nil {
+		return nil, err
+	}
+	person, err := s.opts.Repository.GetPerson(ctx, id, false)
+	if err != nil {
+		return nil, err
+	}
+	if person.Status != model.PersonStatusNeedsReview {
+		return nil, domainErr.ErrNotUnderReview
+	}
+
+	setManual(person, data.Age, data.Gender, data.Nationality)
+	person.Status = model.PersonStatusEnriched
+	person.ReviewReason = ""
+
+	if err := s.opts.Repository.SaveReview(ctx, person); err != nil {
+		logger.Debug("failed to save review", slog.Any("error", err))
+		return nil, err
+	}
+	logger.Debug("person was successfully reviewed")
+	return person, nil
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/Kosodaka/enricher-service/internal/domain/dto"
+	domainErr "github.com/Kosodaka/enricher-service/internal/domain/errors"
+	"github.com/Kosodaka/enricher-service/internal/domain/model"
+	"github.com/Kosodaka/enricher-service/internal/domain/ports/enricher"
+	"github.com/Kosodaka/enricher-service/pkg/logger"
+	mock_enricher "github.com/Kosodaka/enricher-service/pkg/mocks/api/enricher"
+	mock_repository "github.com/Kosodaka/enricher-service/pkg/mocks/api/repository"
+	"github.com/Kosodaka/enricher-service/pkg/policy"
+	"github.com/Kosodaka/enricher-service/pkg/ptr"
+	"github.com/Kosodaka/enricher-service/pkg/validator"
+	"github.com/golang/mock/gomock"
+	"reflect"
+	"testing"
+)
+
+func TestService_AddPersonReviewPolicy(t *testing.T) {
+	reviewPolicy, err := policy.Parse("gender.probability < 0.7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name              string
+		genderProbability float64
+		status            string
+		reason            string
+	}{
+		{
+			name:              "confident person is enriched",
+			genderProbability: 0.9,
+			status:            model.PersonStatusEnriched,
+		}, {
+			name:              "doubtful person is saved for review",
+			genderProbability: 0.6,
+			status:            model.PersonStatusNeedsReview,
+			reason:            "gender.probability < 0.7",
+		},
+	}
+	for _, testCases := range cases {
+		t.Run(testCases.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			persons := mock_repository.NewMockPersonRepository(ctrl)
+			e := mock_enricher.NewMockEnricher(ctrl)
+			svc := NewService()
+			svc.Init(SetLogger(logger.SetupLogger("test")), SetValidator(validator.NewValidator()),
+				SetRepository(persons), SetEnricher(e), SetReviewPolicy(reviewPolicy))
+
+			e.EXPECT().Enrich(gomock.Any(), "Sasha", "").Return(&enricher.EnrichData{
+				Age:               ptr.To(30),
+				Gender:            ptr.To("female"),
+				GenderProbability: testCases.genderProbability,
+				Nationality:       ptr.To("RU"),
+			}, nil)
+			persons.EXPECT().AddPerson(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, person *model.Person) (int, error) {
+				if person.Status != testCases.status || person.ReviewReason != testCases.reason {
+					t.Errorf("got status %q reason %q, want %q %q", person.Status, person.ReviewReason, testCases.status, testCases.reason)
+				}
+				return 3, nil
+			})
+
+			if _, err := svc.AddPerson(context.Background(), &dto.AddPersonDTO{Name: "Sasha", Surname: "Lee"}); err != nil {
+				t.Fatalf("got error %v", err)
+			}
+		})
+	}
+}
+
+func TestService_ReviewPerson(t *testing.T) {
+	underReview := func() *model.Person {
+		return &model.Person{
+			Id:                     4,
+			Name:                   "Sasha",
+			Age:                    ptr.To(30),
+			AgeStatus:              enricher.StatusOk,
+			Gender:                 ptr.To("female"),
+			GenderProbability:      0.6,
+			GenderCount:            120,
+			GenderStatus:           enricher.StatusOk,
+			Nationality:            ptr.To("RU"),
+			NationalityProbability: 0.4,
+			NationalityStatus:      enricher.StatusOk,
+			Nationalities:          []model.PersonNationality{{CountryId: "RU", Probability: 0.4, Rank: 1}},
+			Status:                 model.PersonStatusNeedsReview,
+			ReviewReason:           "gender.probability < 0.7",
+		}
+	}
+	cases := []struct {
+		name   string
+		stored func() *model.Person
+		data   *dto.ReviewDTO
+		want   func() *model.Person
+		err    error
+	}{
+		{
+			name:   "confirmation keeps the predictions",
+			stored: underReview,
+			data:   &dto.ReviewDTO{},
+			want: func() *model.Person {
+				person := underReview()
+				person.Status = model.PersonStatusEnriched
+				person.ReviewReason = ""
+				return person
+			},
+		}, {
+			name:   "correction verifies the attribute",
+			stored: underReview,
+			data:   &dto.ReviewDTO{Gender: ptr.To("male")},
+			want: func() *model.Person {
+				person := underReview()
+				person.Gender = ptr.To("male")
+				person.GenderProbability = 1
+				person.GenderCount = 0
+				person.GenderVerified = true
+				person.GenderSource = model.SourceManual
+				person.Status = model.PersonStatusEnriched
+				person.ReviewReason = ""
+				return person
+			},
+		}, {
+			name: "person not under review",
+			stored: func() *model.Person {
+				person := underReview()
+				person.Status = model.PersonStatusEnriched
+				return person
+			},
+			data: &dto.ReviewDTO{},
+			err:  domainErr.ErrNotUnderReview,
+		},
+	}
+	for _, testCases := range cases {
+		t.Run(testCases.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			persons := mock_repository.NewMockPersonRepository(ctrl)
+			svc := NewService()
+			svc.Init(SetLogger(logger.SetupLogger("test")), SetValidator(validator.NewValidator()), SetRepository(persons))
+
+			persons.EXPECT().GetPerson(gomock.Any(), 4, false).Return(testCases.stored(), nil)
+			if testCases.want != nil {
+				persons.EXPECT().SaveReview(gomock.Any(), testCases.want()).Return(nil)
+			}
+
+			person, err := svc.ReviewPerson(context.Background(), 4, testCases.data)
+			if !errors.Is(err, testCases.err) {
+				t.Fatalf("got %v, want %v", err, testCases.err)
+			}
+			if testCases.want != nil && !reflect.DeepEqual(person, testCases.want()) {
+				t.Errorf("got %+v, want %+v", person, testCases.want())
+			}
+		})
+	}
+}
+
+func TestService_ReenrichPersonKeepsVerified(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	persons := mock_repository.NewMockPersonRepository(ctrl)
+	e := mock_enricher.NewMockEnricher(ctrl)
+	svc := NewService()
+	svc.Init(SetLogger(logger.SetupLogger("test")), SetValidator(validator.NewValidator()),
+		SetRepository(persons), SetEnricher(e))
+
+	stored := &model.Person{
+		Id:                4,
+		Name:              "Sasha",
+		NameLatin:         "Sasha",
+		Gender:            ptr.To("male"),
+		GenderProbability: 1,
+		GenderStatus:      enricher.StatusOk,
+		GenderVerified:    true,
+		Status:            model.PersonStatusEnriched,
+	}
+	persons.EXPECT().GetPerson(gomock.Any(), 4, false).Return(stored, nil)
+	// The verified gender is not asked for.
+	e.EXPECT().Enrich(gomock.Any(), "Sasha", "", enricher.AttributeGender).Return(&enricher.EnrichData{
+		Age:               ptr.To(25),
+		AgeStatus:         enricher.StatusOk,
+		GenderStatus:      enricher.StatusNotRequested,
+		Nationality:       ptr.To("RU"),
+		NationalityStatus: enricher.StatusOk,
+	}, nil)
+	persons.EXPECT().UpdateEnrichment(gomock.Any(), gomock.Any()).Return(nil)
+
+	person, err := svc.ReenrichPerson(context.Background(), 4)
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+	if *person.Gender != "male" || person.GenderProbability != 1 || person.GenderStatus != enricher.StatusOk {
+		t.Errorf("got gender %s %v %s, want the verified one kept", *person.Gender, person.GenderProbability, person.GenderStatus)
+	}
+	if person.Age == nil || *person.Age != 25 {
+		t.Errorf("got age %v, want 25", person.Age)
+	}
+}
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE enrichment_cache (
+                         name VARCHAR(100) PRIMARY KEY,
+                         data jsonb not null,
+                         updated_at timestamptz not null default now()
+);
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE enrichment_cache;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE person
+    ADD COLUMN age_count int not null default 0,
+    ADD COLUMN gender_probability double precision not null default 0,
+    ADD COLUMN gender_count int not null default 0,
+    ADD COLUMN nationality_probability double precision not null default 0,
+    ADD COLUMN nationality_count int not null default 0;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE person
+    DROP COLUMN age_count,
+    DROP COLUMN gender_probability,
+    DROP COLUMN gender_count,
+    DROP COLUMN nationality_probability,
+    DROP COLUMN nationality_count;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE person ADD PRIMARY KEY (id);
+
+CREATE TABLE person_nationality (
+                         person_id bigint not null REFERENCES person (id) ON DELETE CASCADE,
+                         country_id VARCHAR(2) not null,
+                         probability double precision not null,
+                         rank int not null,
+                         PRIMARY KEY (person_id, rank)
+);
+
+CREATE INDEX person_nationality_country_idx ON person_nationality (country_id, probability);
+
+INSERT INTO person_nationality (person_id, country_id, probability, rank)
+SELECT id, nationality, nationality_probability, 1 FROM person;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE person_nationality;
+
+ALTER TABLE person DROP CONSTRAINT person_pkey;
+-- +goose StatementEnd
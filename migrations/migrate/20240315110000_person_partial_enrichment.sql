@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE person
+    ALTER COLUMN age DROP NOT NULL,
+    ALTER COLUMN gender DROP NOT NULL,
+    ALTER COLUMN nationality DROP NOT NULL,
+    ADD COLUMN age_status VARCHAR(16) not null default 'ok',
+    ADD COLUMN gender_status VARCHAR(16) not null default 'ok',
+    ADD COLUMN nationality_status VARCHAR(16) not null default 'ok';
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM person WHERE age IS NULL OR gender IS NULL OR nationality IS NULL;
+
+ALTER TABLE person
+    DROP COLUMN age_status,
+    DROP COLUMN gender_status,
+    DROP COLUMN nationality_status,
+    ALTER COLUMN age SET NOT NULL,
+    ALTER COLUMN gender SET NOT NULL,
+    ALTER COLUMN nationality SET NOT NULL;
+-- +goose StatementEnd
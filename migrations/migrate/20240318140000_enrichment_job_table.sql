@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE person ADD COLUMN status VARCHAR(16) not null default 'enriched';
+
+CREATE TABLE enrichment_job (
+                         id bigserial PRIMARY KEY,
+                         person_id bigint not null REFERENCES person (id) ON DELETE CASCADE,
+                         status VARCHAR(16) not null,
+                         attempts int not null default 0,
+                         error text,
+                         created_at timestamptz not null default now(),
+                         updated_at timestamptz not null default now()
+);
+
+CREATE INDEX enrichment_job_queue_idx ON enrichment_job (status, id);
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE enrichment_job;
+
+ALTER TABLE person DROP COLUMN status;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE provider_usage (
+                         provider VARCHAR(32) not null,
+                         day date not null,
+                         used int not null default 0,
+                         rate_limit int,
+                         remaining int,
+                         reset_at timestamptz,
+                         updated_at timestamptz not null default now(),
+                         PRIMARY KEY (provider, day)
+);
+
+ALTER TABLE enrichment_job ADD COLUMN available_at timestamptz not null default now();
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE enrichment_job DROP COLUMN available_at;
+
+DROP TABLE provider_usage;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE person
+    ADD COLUMN age_country_id VARCHAR(2) not null default '',
+    ADD COLUMN gender_country_id VARCHAR(2) not null default '';
+
+ALTER TABLE enrichment_job ADD COLUMN country_id VARCHAR(2) not null default '';
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE enrichment_job DROP COLUMN country_id;
+
+ALTER TABLE person
+    DROP COLUMN age_country_id,
+    DROP COLUMN gender_country_id;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Transliteration may make a name four times longer, щ becomes shch.
+ALTER TABLE person
+    ADD COLUMN name_latin VARCHAR(400) not null default '',
+    ADD COLUMN surname_latin VARCHAR(400) not null default '',
+    ADD COLUMN patronymic_latin VARCHAR(400) not null default '';
+
+-- Names used to be validated as plain Latin ones.
+UPDATE person SET name_latin = name, surname_latin = surname, patronymic_latin = coalesce(patronymic, '');
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE person
+    DROP COLUMN name_latin,
+    DROP COLUMN surname_latin,
+    DROP COLUMN patronymic_latin;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE person ADD COLUMN enriched_at timestamptz;
+
+CREATE TABLE reenrich_run (
+                         id bigserial PRIMARY KEY,
+                         status VARCHAR(16) not null,
+                         total int not null default 0,
+                         created_at timestamptz not null default now(),
+                         updated_at timestamptz not null default now()
+);
+
+ALTER TABLE enrichment_job ADD COLUMN run_id bigint REFERENCES reenrich_run (id) ON DELETE CASCADE;
+
+CREATE INDEX enrichment_job_run_idx ON enrichment_job (run_id, status) WHERE run_id IS NOT NULL;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM enrichment_job WHERE run_id IS NOT NULL;
+
+ALTER TABLE enrichment_job DROP COLUMN run_id;
+
+DROP TABLE reenrich_run;
+
+ALTER TABLE person DROP COLUMN enriched_at;
+-- +goose StatementEnd
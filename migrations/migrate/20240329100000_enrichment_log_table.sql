@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE enrichment_log (
+                         id bigserial PRIMARY KEY,
+                         person_id bigint not null REFERENCES person (id) ON DELETE CASCADE,
+                         provider VARCHAR(32) not null,
+                         url text not null,
+                         status int not null,
+                         headers jsonb not null default '{}',
+                         body text not null,
+                         latency_ms bigint not null,
+                         created_at timestamptz not null default now()
+);
+
+CREATE INDEX enrichment_log_person_idx ON enrichment_log (person_id, id);
+CREATE INDEX enrichment_log_created_idx ON enrichment_log (created_at);
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE enrichment_log;
+-- +goose StatementEnd
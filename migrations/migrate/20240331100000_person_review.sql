@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE person ADD COLUMN review_reason text not null default '';
+ALTER TABLE person ADD COLUMN age_verified boolean not null default false;
+ALTER TABLE person ADD COLUMN gender_verified boolean not null default false;
+ALTER TABLE person ADD COLUMN nationality_verified boolean not null default false;
+
+CREATE INDEX person_review_idx ON person (id) WHERE status = 'needs_review';
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX person_review_idx;
+
+UPDATE person SET status = 'enriched' WHERE status = 'needs_review';
+
+ALTER TABLE person DROP COLUMN nationality_verified;
+ALTER TABLE person DROP COLUMN gender_verified;
+ALTER TABLE person DROP COLUMN age_verified;
+ALTER TABLE person DROP COLUMN review_reason;
+-- +goose StatementEnd
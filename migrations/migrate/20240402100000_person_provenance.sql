@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE person ADD COLUMN age_source VARCHAR(16) not null default '';
+ALTER TABLE person ADD COLUMN gender_source VARCHAR(16) not null default '';
+ALTER TABLE person ADD COLUMN nationality_source VARCHAR(16) not null default '';
+
+UPDATE person SET age_source = 'manual' WHERE age_verified;
+UPDATE person SET gender_source = 'manual' WHERE gender_verified;
+UPDATE person SET nationality_source = 'manual' WHERE nationality_verified;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE person DROP COLUMN nationality_source;
+ALTER TABLE person DROP COLUMN gender_source;
+ALTER TABLE person DROP COLUMN age_source;
+-- +goose StatementEnd
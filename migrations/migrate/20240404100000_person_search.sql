@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The search ranks persons in memory where pg_trgm may not be installed.
+DO $$
+BEGIN
+    CREATE EXTENSION IF NOT EXISTS pg_trgm;
+    CREATE INDEX person_full_name_trgm_idx ON person
+        USING GIN ((name || ' ' || surname || ' ' || coalesce(patronymic, '')) gin_trgm_ops);
+EXCEPTION WHEN insufficient_privilege OR undefined_file THEN
+    RAISE NOTICE 'pg_trgm is not available, persons are searched without the index';
+END
+$$;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS person_full_name_trgm_idx;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE person ADD COLUMN deleted_at timestamptz;
+
+CREATE INDEX person_deleted_at_idx ON person (deleted_at) WHERE deleted_at IS NOT NULL;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM person WHERE deleted_at IS NOT NULL;
+DROP INDEX person_deleted_at_idx;
+ALTER TABLE person DROP COLUMN deleted_at;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE person ADD COLUMN version bigint not null default 1;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE person DROP COLUMN version;
+-- +goose StatementEnd
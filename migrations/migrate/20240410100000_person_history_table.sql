@@ -0,0 +1,63 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The history outlives purged persons, so it does not reference them.
+CREATE TABLE person_history (
+                         id bigserial primary key,
+                         person_id bigint not null,
+                         operation VARCHAR(8) not null,
+                         before jsonb,
+                         after jsonb,
+                         actor text not null,
+                         request_id text not null default '',
+                         created_at timestamptz not null default now()
+);
+
+CREATE INDEX person_history_person_id_idx ON person_history (person_id, created_at);
+
+-- The history starts with the state of every existing person at the time of this migration,
+-- encoded as the API returns a person: the id and the age are strings.
+INSERT INTO person_history (person_id, operation, after, actor)
+SELECT p.id, 'insert', jsonb_build_object(
+                             'id', p.id::text,
+                             'name', p.name,
+                             'surname', p.surname,
+                             'patronymic', p.patronymic,
+                             'name_latin', p.name_latin,
+                             'surname_latin', p.surname_latin,
+                             'patronymic_latin', p.patronymic_latin,
+                             'age', p.age::text,
+                             'age_count', p.age_count,
+                             'age_status', p.age_status,
+                             'age_country_id', p.age_country_id,
+                             'gender', p.gender,
+                             'gender_probability', p.gender_probability,
+                             'gender_count', p.gender_count,
+                             'gender_status', p.gender_status,
+                             'gender_country_id', p.gender_country_id,
+                             'nationality', p.nationality,
+                             'nationality_probability', p.nationality_probability,
+                             'nationality_count', p.nationality_count,
+                             'nationality_status', p.nationality_status,
+                             'status', p.status,
+                             'enriched_at', p.enriched_at,
+                             'review_reason', p.review_reason,
+                             'age_verified', p.age_verified,
+                             'gender_verified', p.gender_verified,
+                             'nationality_verified', p.nationality_verified,
+                             'age_source', p.age_source,
+                             'gender_source', p.gender_source,
+                             'nationality_source', p.nationality_source,
+                             'version', p.version,
+                             'deleted_at', p.deleted_at,
+                             'nationalities', coalesce((SELECT jsonb_agg(jsonb_build_object(
+                                 'country_id', pn.country_id, 'probability', pn.probability, 'rank', pn.rank) ORDER BY pn.rank)
+                                 FROM person_nationality pn WHERE pn.person_id = p.id), '[]'::jsonb)),
+       'system'
+FROM person p;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE person_history;
+-- +goose StatementEnd
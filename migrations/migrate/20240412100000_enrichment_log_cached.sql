@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE enrichment_log ADD COLUMN cached boolean not null default false;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE enrichment_log DROP COLUMN cached;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The indexes follow the ascending order of the person list: the field with missing values last, then id.
+CREATE INDEX person_name_sort_idx ON person (name ASC NULLS LAST, id);
+CREATE INDEX person_surname_sort_idx ON person (surname ASC NULLS LAST, id);
+CREATE INDEX person_patronymic_sort_idx ON person (patronymic ASC NULLS LAST, id);
+CREATE INDEX person_age_sort_idx ON person (age ASC NULLS LAST, id);
+CREATE INDEX person_gender_sort_idx ON person (gender ASC NULLS LAST, id);
+CREATE INDEX person_nationality_sort_idx ON person (nationality ASC NULLS LAST, id);
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX person_name_sort_idx;
+DROP INDEX person_surname_sort_idx;
+DROP INDEX person_patronymic_sort_idx;
+DROP INDEX person_age_sort_idx;
+DROP INDEX person_gender_sort_idx;
+DROP INDEX person_nationality_sort_idx;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The Latin forms were sized for three letters per Cyrillic one, щ now becomes shch.
+ALTER TABLE person
+    ALTER COLUMN name_latin TYPE VARCHAR(400),
+    ALTER COLUMN surname_latin TYPE VARCHAR(400),
+    ALTER COLUMN patronymic_latin TYPE VARCHAR(400);
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- Narrowing the columns back could cut stored names, so they keep their length.
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The descending order of the person list keeps missing values last and id ascending,
+-- so it cannot scan the ascending indexes backward and needs its own.
+CREATE INDEX person_name_sort_desc_idx ON person (name DESC NULLS LAST, id);
+CREATE INDEX person_surname_sort_desc_idx ON person (surname DESC NULLS LAST, id);
+CREATE INDEX person_patronymic_sort_desc_idx ON person (patronymic DESC NULLS LAST, id);
+CREATE INDEX person_age_sort_desc_idx ON person (age DESC NULLS LAST, id);
+CREATE INDEX person_gender_sort_desc_idx ON person (gender DESC NULLS LAST, id);
+CREATE INDEX person_nationality_sort_desc_idx ON person (nationality DESC NULLS LAST, id);
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX person_name_sort_desc_idx;
+DROP INDEX person_surname_sort_desc_idx;
+DROP INDEX person_patronymic_sort_desc_idx;
+DROP INDEX person_age_sort_desc_idx;
+DROP INDEX person_gender_sort_desc_idx;
+DROP INDEX person_nationality_sort_desc_idx;
+-- +goose StatementEnd
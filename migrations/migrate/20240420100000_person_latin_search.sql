@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The search matches the Latin forms of the names too.
+DO $$
+BEGIN
+    CREATE EXTENSION IF NOT EXISTS pg_trgm;
+    CREATE INDEX person_full_name_latin_trgm_idx ON person
+        USING GIN ((name_latin || ' ' || surname_latin || ' ' || patronymic_latin) gin_trgm_ops);
+EXCEPTION WHEN insufficient_privilege OR undefined_file THEN
+    RAISE NOTICE 'pg_trgm is not available, persons are searched without the index';
+END
+$$;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS person_full_name_latin_trgm_idx;
+-- +goose StatementEnd
@@ -0,0 +1,39 @@
+package audit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// SystemActor is the actor of the changes made without a request, e.g. by the workers.
+const SystemActor = "system"
+
+type originKey struct{}
+
+type origin struct {
+	actor     string
+	requestId string
+}
+
+// WithOrigin returns a context carrying who made the request changing persons and its id.
+func WithOrigin(ctx context.Context, actor, requestId string) context.Context {
+	return context.WithValue(ctx, originKey{}, origin{actor: actor, requestId: requestId})
+}
+
+// FromContext returns the actor and the request id of the context, the actor is
+// SystemActor when the context carries none.
+func FromContext(ctx context.Context) (actor, requestId string) {
+	o, _ := ctx.Value(originKey{}).(origin)
+	if o.actor == "" {
+		o.actor = SystemActor
+	}
+	return o.actor, o.requestId
+}
+
+// NewRequestId returns a random id for a request that came without one.
+func NewRequestId() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
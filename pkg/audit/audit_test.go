@@ -0,0 +1,39 @@
+package audit
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFromContext(t *testing.T) {
+	cases := []struct {
+		name      string
+		ctx       context.Context
+		actor     string
+		requestId string
+	}{
+		{
+			name:      "request",
+			ctx:       WithOrigin(context.Background(), "olga", "42"),
+			actor:     "olga",
+			requestId: "42",
+		}, {
+			name:      "anonymous request",
+			ctx:       WithOrigin(context.Background(), "", "42"),
+			actor:     SystemActor,
+			requestId: "42",
+		}, {
+			name:  "worker",
+			ctx:   context.Background(),
+			actor: SystemActor,
+		},
+	}
+	for _, testCases := range cases {
+		t.Run(testCases.name, func(t *testing.T) {
+			actor, requestId := FromContext(testCases.ctx)
+			if actor != testCases.actor || requestId != testCases.requestId {
+				t.Errorf("got %q, %q, want %q, %q", actor, requestId, testCases.actor, testCases.requestId)
+			}
+		})
+	}
+}
@@ -4,16 +4,47 @@ import (
 	"fmt"
 	"github.com/joho/godotenv"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
-	PostgresDSN       string
-	Env               string
-	HttpPort          string
-	HttpHost          string
-	AgeApiUrl         string
-	GenderApiUrl      string
-	NationalityApiUrl string
+	PostgresDSN          string
+	Env                  string
+	HttpPort             string
+	HttpHost             string
+	AgeApiUrl            string
+	GenderApiUrl         string
+	NationalityApiUrl    string
+	EnrichCacheSize      int
+	EnrichCacheTTL       time.Duration
+	RetryAttempts        int
+	RetryBaseDelay       time.Duration
+	RetryMaxDelay        time.Duration
+	BreakerThreshold     int
+	BreakerOpenTimeout   time.Duration
+	AgeProviders         []string
+	GenderProviders      []string
+	NationalityProviders []string
+	DefaultAge           int
+	DefaultGender        string
+	DefaultNationality   string
+	PartialEnrichment    bool
+	EnrichWorkers        int
+	EnrichPollInterval   time.Duration
+	EnrichJobAttempts    int
+	EnrichJobLease       time.Duration
+	DatasetPath          string
+	DatasetFallback      bool
+	DailyBudget          int
+	UsageSaveInterval    time.Duration
+	DefaultCountryId     string
+	NameRulesConfidence  float64
+	EnrichLogRetention   time.Duration
+	PruneInterval        time.Duration
+	ReviewPolicy         string
+	DeletedRetention     time.Duration
 }
 
 func (c *Config) GetHTTPPort() string {
@@ -34,6 +65,118 @@ func (c *Config) GetNationalityApiURL() string {
 	return c.NationalityApiUrl
 }
 
+func (c *Config) GetEnrichCacheSize() int {
+	return c.EnrichCacheSize
+}
+
+func (c *Config) GetEnrichCacheTTL() time.Duration {
+	return c.EnrichCacheTTL
+}
+
+func (c *Config) GetRetryAttempts() int {
+	return c.RetryAttempts
+}
+
+func (c *Config) GetRetryBaseDelay() time.Duration {
+	return c.RetryBaseDelay
+}
+
+func (c *Config) GetRetryMaxDelay() time.Duration {
+	return c.RetryMaxDelay
+}
+
+func (c *Config) GetBreakerThreshold() int {
+	return c.BreakerThreshold
+}
+
+func (c *Config) GetBreakerOpenTimeout() time.Duration {
+	return c.BreakerOpenTimeout
+}
+
+func (c *Config) GetAgeProviders() []string {
+	return c.AgeProviders
+}
+
+func (c *Config) GetGenderProviders() []string {
+	return c.GenderProviders
+}
+
+func (c *Config) GetNationalityProviders() []string {
+	return c.NationalityProviders
+}
+
+func (c *Config) GetDefaultAge() int {
+	return c.DefaultAge
+}
+
+func (c *Config) GetDefaultGender() string {
+	return c.DefaultGender
+}
+
+func (c *Config) GetDefaultNationality() string {
+	return c.DefaultNationality
+}
+
+func (c *Config) GetPartialEnrichment() bool {
+	return c.PartialEnrichment
+}
+
+func (c *Config) GetEnrichWorkers() int {
+	return c.EnrichWorkers
+}
+
+func (c *Config) GetEnrichPollInterval() time.Duration {
+	return c.EnrichPollInterval
+}
+
+func (c *Config) GetEnrichJobAttempts() int {
+	return c.EnrichJobAttempts
+}
+
+func (c *Config) GetEnrichJobLease() time.Duration {
+	return c.EnrichJobLease
+}
+
+func (c *Config) GetDatasetPath() string {
+	return c.DatasetPath
+}
+
+func (c *Config) GetDatasetFallback() bool {
+	return c.DatasetFallback
+}
+
+func (c *Config) GetDailyBudget() int {
+	return c.DailyBudget
+}
+
+func (c *Config) GetUsageSaveInterval() time.Duration {
+	return c.UsageSaveInterval
+}
+
+func (c *Config) GetDefaultCountryId() string {
+	return c.DefaultCountryId
+}
+
+func (c *Config) GetNameRulesConfidence() float64 {
+	return c.NameRulesConfidence
+}
+
+func (c *Config) GetEnrichLogRetention() time.Duration {
+	return c.EnrichLogRetention
+}
+
+func (c *Config) GetPruneInterval() time.Duration {
+	return c.PruneInterval
+}
+
+func (c *Config) GetDeletedRetention() time.Duration {
+	return c.DeletedRetention
+}
+
+func (c *Config) GetReviewPolicy() string {
+	return c.ReviewPolicy
+}
+
 func LoadEnv(filenames ...string) error {
 	const op = "pkg.config.LoadEnv"
 	err := godotenv.Load(filenames...)
@@ -45,12 +188,34 @@ func LoadEnv(filenames ...string) error {
 
 func LoadConfig() *Config {
 	cfg := &Config{
-		PostgresDSN:       "",
-		Env:               "local",
-		HttpHost:          "localhost",
-		AgeApiUrl:         "https://api.agify.io/",
-		GenderApiUrl:      "https://api.genderize.io/",
-		NationalityApiUrl: "https://api.nationalize.io/",
+		PostgresDSN:          "",
+		Env:                  "local",
+		HttpHost:             "localhost",
+		AgeApiUrl:            "https://api.agify.io/",
+		GenderApiUrl:         "https://api.genderize.io/",
+		NationalityApiUrl:    "https://api.nationalize.io/",
+		EnrichCacheSize:      10000,
+		EnrichCacheTTL:       24 * time.Hour,
+		RetryAttempts:        3,
+		RetryBaseDelay:       200 * time.Millisecond,
+		RetryMaxDelay:        5 * time.Second,
+		BreakerThreshold:     5,
+		BreakerOpenTimeout:   30 * time.Second,
+		AgeProviders:         []string{"agify"},
+		GenderProviders:      []string{"genderize"},
+		NationalityProviders: []string{"nationalize"},
+		PartialEnrichment:    false,
+		EnrichWorkers:        4,
+		EnrichPollInterval:   time.Second,
+		EnrichJobAttempts:    3,
+		EnrichJobLease:       5 * time.Minute,
+		DatasetFallback:      false,
+		UsageSaveInterval:    30 * time.Second,
+		NameRulesConfidence:  0.9,
+		EnrichLogRetention:   30 * 24 * time.Hour,
+		PruneInterval:        time.Hour,
+		ReviewPolicy:         "",
+		DeletedRetention:     30 * 24 * time.Hour,
 	}
 
 	postgresDsn := os.Getenv("DSN")
@@ -60,6 +225,34 @@ func LoadConfig() *Config {
 	ageUrl := os.Getenv("AGE_API_URL")
 	genderUrl := os.Getenv("GENDER_API_URL")
 	nationalityUrl := os.Getenv("NATIONALITY_API_URL")
+	cacheSize := os.Getenv("ENRICH_CACHE_SIZE")
+	cacheTTL := os.Getenv("ENRICH_CACHE_TTL")
+	retryAttempts := os.Getenv("ENRICH_RETRY_ATTEMPTS")
+	retryBaseDelay := os.Getenv("ENRICH_RETRY_BASE_DELAY")
+	retryMaxDelay := os.Getenv("ENRICH_RETRY_MAX_DELAY")
+	breakerThreshold := os.Getenv("BREAKER_FAILURE_THRESHOLD")
+	breakerOpenTimeout := os.Getenv("BREAKER_OPEN_TIMEOUT")
+	ageProviders := os.Getenv("AGE_PROVIDERS")
+	genderProviders := os.Getenv("GENDER_PROVIDERS")
+	nationalityProviders := os.Getenv("NATIONALITY_PROVIDERS")
+	defaultAge := os.Getenv("DEFAULT_AGE")
+	defaultGender := os.Getenv("DEFAULT_GENDER")
+	defaultNationality := os.Getenv("DEFAULT_NATIONALITY")
+	partialEnrichment := os.Getenv("ENRICH_PARTIAL")
+	enrichWorkers := os.Getenv("ENRICH_WORKERS")
+	enrichPollInterval := os.Getenv("ENRICH_POLL_INTERVAL")
+	enrichJobAttempts := os.Getenv("ENRICH_JOB_ATTEMPTS")
+	enrichJobLease := os.Getenv("ENRICH_JOB_LEASE")
+	datasetPath := os.Getenv("DATASET_PATH")
+	datasetFallback := os.Getenv("DATASET_FALLBACK")
+	dailyBudget := os.Getenv("ENRICH_DAILY_BUDGET")
+	usageSaveInterval := os.Getenv("PROVIDER_USAGE_SAVE_INTERVAL")
+	defaultCountryId := os.Getenv("DEFAULT_COUNTRY_ID")
+	nameRulesConfidence := os.Getenv("NAME_RULES_MIN_CONFIDENCE")
+	enrichmentLogRetention := os.Getenv("ENRICHMENT_LOG_RETENTION")
+	pruneInterval := os.Getenv("PRUNE_INTERVAL")
+	reviewPolicy, reviewPolicySet := os.LookupEnv("REVIEW_POLICY")
+	deletedRetention := os.Getenv("DELETED_PERSON_RETENTION")
 
 	if postgresDsn != "" {
 		cfg.PostgresDSN = postgresDsn
@@ -82,6 +275,101 @@ func LoadConfig() *Config {
 	if nationalityUrl != "" {
 		cfg.NationalityApiUrl = nationalityUrl
 	}
+	if size, err := strconv.Atoi(cacheSize); err == nil && size > 0 {
+		cfg.EnrichCacheSize = size
+	}
+	if ttl, err := time.ParseDuration(cacheTTL); err == nil {
+		cfg.EnrichCacheTTL = ttl
+	}
+	if attempts, err := strconv.Atoi(retryAttempts); err == nil && attempts > 0 {
+		cfg.RetryAttempts = attempts
+	}
+	if delay, err := time.ParseDuration(retryBaseDelay); err == nil {
+		cfg.RetryBaseDelay = delay
+	}
+	if delay, err := time.ParseDuration(retryMaxDelay); err == nil {
+		cfg.RetryMaxDelay = delay
+	}
+	if threshold, err := strconv.Atoi(breakerThreshold); err == nil && threshold > 0 {
+		cfg.BreakerThreshold = threshold
+	}
+	if timeout, err := time.ParseDuration(breakerOpenTimeout); err == nil {
+		cfg.BreakerOpenTimeout = timeout
+	}
+	if providers := splitList(ageProviders); len(providers) > 0 {
+		cfg.AgeProviders = providers
+	}
+	if providers := splitList(genderProviders); len(providers) > 0 {
+		cfg.GenderProviders = providers
+	}
+	if providers := splitList(nationalityProviders); len(providers) > 0 {
+		cfg.NationalityProviders = providers
+	}
+	if age, err := strconv.Atoi(defaultAge); err == nil && age > 0 {
+		cfg.DefaultAge = age
+	}
+	if defaultGender != "" {
+		cfg.DefaultGender = defaultGender
+	}
+	if defaultNationality != "" {
+		cfg.DefaultNationality = defaultNationality
+	}
+	if partial, err := strconv.ParseBool(partialEnrichment); err == nil {
+		cfg.PartialEnrichment = partial
+	}
+	if workers, err := strconv.Atoi(enrichWorkers); err == nil && workers > 0 {
+		cfg.EnrichWorkers = workers
+	}
+	if interval, err := time.ParseDuration(enrichPollInterval); err == nil && interval > 0 {
+		cfg.EnrichPollInterval = interval
+	}
+	if attempts, err := strconv.Atoi(enrichJobAttempts); err == nil && attempts > 0 {
+		cfg.EnrichJobAttempts = attempts
+	}
+	if lease, err := time.ParseDuration(enrichJobLease); err == nil && lease > 0 {
+		cfg.EnrichJobLease = lease
+	}
+	if datasetPath != "" {
+		cfg.DatasetPath = datasetPath
+	}
+	if fallback, err := strconv.ParseBool(datasetFallback); err == nil {
+		cfg.DatasetFallback = fallback
+	}
+	if budget, err := strconv.Atoi(dailyBudget); err == nil && budget >= 0 {
+		cfg.DailyBudget = budget
+	}
+	if interval, err := time.ParseDuration(usageSaveInterval); err == nil && interval > 0 {
+		cfg.UsageSaveInterval = interval
+	}
+	if defaultCountryId != "" {
+		cfg.DefaultCountryId = strings.ToUpper(strings.TrimSpace(defaultCountryId))
+	}
+	if confidence, err := strconv.ParseFloat(nameRulesConfidence, 64); err == nil && confidence > 0 {
+		cfg.NameRulesConfidence = confidence
+	}
+	if retention, err := time.ParseDuration(enrichmentLogRetention); err == nil && retention >= 0 {
+		cfg.EnrichLogRetention = retention
+	}
+	if interval, err := time.ParseDuration(pruneInterval); err == nil && interval > 0 {
+		cfg.PruneInterval = interval
+	}
+	if reviewPolicySet {
+		cfg.ReviewPolicy = reviewPolicy
+	}
+	if retention, err := time.ParseDuration(deletedRetention); err == nil && retention >= 0 {
+		cfg.DeletedRetention = retention
+	}
 
 	return cfg
 }
+
+// splitList parses a comma separated env value such as "agify,static".
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
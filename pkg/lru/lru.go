@@ -0,0 +1,93 @@
+package lru
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Cache is a size-bounded LRU cache whose entries expire after ttl.
+// A zero or negative ttl disables expiration. Cache is safe for concurrent use.
+type Cache[K comparable, V any] struct {
+	mu    sync.Mutex
+	size  int
+	ttl   time.Duration
+	ll    *list.List
+	items map[K]*list.Element
+}
+
+type entry[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time
+}
+
+func New[K comparable, V any](size int, ttl time.Duration) *Cache[K, V] {
+	if size <= 0 {
+		size = 1
+	}
+	return &Cache[K, V]{
+		size:  size,
+		ttl:   ttl,
+		ll:    list.New(),
+		items: make(map[K]*list.Element, size),
+	}
+}
+
+// Get returns the value stored under key and marks it as recently used.
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+	el, ok := c.items[key]
+	if !ok {
+		return zero, false
+	}
+	e := el.Value.(*entry[K, V])
+	if c.ttl > 0 && time.Now().After(e.expiresAt) {
+		c.removeElement(el)
+		return zero, false
+	}
+	c.ll.MoveToFront(el)
+	return e.value, true
+}
+
+// Add stores value under key, evicting the least recently used entry when full.
+func (c *Cache[K, V]) Add(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := time.Now().Add(c.ttl)
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*entry[K, V])
+		e.value = value
+		e.expiresAt = expiresAt
+		c.ll.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.ll.PushFront(&entry[K, V]{key: key, value: value, expiresAt: expiresAt})
+	if c.ll.Len() > c.size {
+		c.removeElement(c.ll.Back())
+	}
+}
+
+func (c *Cache[K, V]) Remove(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.removeElement(el)
+	}
+}
+
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+func (c *Cache[K, V]) removeElement(el *list.Element) {
+	c.ll.Remove(el)
+	delete(c.items, el.Value.(*entry[K, V]).key)
+}
@@ -36,16 +36,110 @@ func (m *MockEnricher) EXPECT() *MockEnricherMockRecorder {
 }
 
 // Enrich mocks base method.
-func (m *MockEnricher) Enrich(arg0 context.Context, arg1 string) (*enricher.EnrichData, error) {
+func (m *MockEnricher) Enrich(ctx context.Context, name, countryId string, skip ...string) (*enricher.EnrichData, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Enrich", arg0, arg1)
+	varargs := []interface{}{ctx, name, countryId}
+	for _, a := range skip {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Enrich", varargs...)
 	ret0, _ := ret[0].(*enricher.EnrichData)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Enrich indicates an expected call of Enrich.
-func (mr *MockEnricherMockRecorder) Enrich(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockEnricherMockRecorder) Enrich(ctx, name, countryId interface{}, skip ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enrich", reflect.TypeOf((*MockEnricher)(nil).Enrich), arg0, arg1)
+	varargs := append([]interface{}{ctx, name, countryId}, skip...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enrich", reflect.TypeOf((*MockEnricher)(nil).Enrich), varargs...)
+}
+
+// EnrichBatch mocks base method.
+func (m *MockEnricher) EnrichBatch(ctx context.Context, names []string, countryId string) (map[string]enricher.BatchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnrichBatch", ctx, names, countryId)
+	ret0, _ := ret[0].(map[string]enricher.BatchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnrichBatch indicates an expected call of EnrichBatch.
+func (mr *MockEnricherMockRecorder) EnrichBatch(ctx, names, countryId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnrichBatch", reflect.TypeOf((*MockEnricher)(nil).EnrichBatch), ctx, names, countryId)
+}
+
+// MockStatusReporter is a mock of StatusReporter interface.
+type MockStatusReporter struct {
+	ctrl     *gomock.Controller
+	recorder *MockStatusReporterMockRecorder
+}
+
+// MockStatusReporterMockRecorder is the mock recorder for MockStatusReporter.
+type MockStatusReporterMockRecorder struct {
+	mock *MockStatusReporter
+}
+
+// NewMockStatusReporter creates a new mock instance.
+func NewMockStatusReporter(ctrl *gomock.Controller) *MockStatusReporter {
+	mock := &MockStatusReporter{ctrl: ctrl}
+	mock.recorder = &MockStatusReporterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStatusReporter) EXPECT() *MockStatusReporterMockRecorder {
+	return m.recorder
+}
+
+// ProviderStatuses mocks base method.
+func (m *MockStatusReporter) ProviderStatuses() []enricher.ProviderStatus {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProviderStatuses")
+	ret0, _ := ret[0].([]enricher.ProviderStatus)
+	return ret0
+}
+
+// ProviderStatuses indicates an expected call of ProviderStatuses.
+func (mr *MockStatusReporterMockRecorder) ProviderStatuses() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProviderStatuses", reflect.TypeOf((*MockStatusReporter)(nil).ProviderStatuses))
+}
+
+// MockQuotaReporter is a mock of QuotaReporter interface.
+type MockQuotaReporter struct {
+	ctrl     *gomock.Controller
+	recorder *MockQuotaReporterMockRecorder
+}
+
+// MockQuotaReporterMockRecorder is the mock recorder for MockQuotaReporter.
+type MockQuotaReporterMockRecorder struct {
+	mock *MockQuotaReporter
+}
+
+// NewMockQuotaReporter creates a new mock instance.
+func NewMockQuotaReporter(ctrl *gomock.Controller) *MockQuotaReporter {
+	mock := &MockQuotaReporter{ctrl: ctrl}
+	mock.recorder = &MockQuotaReporterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockQuotaReporter) EXPECT() *MockQuotaReporterMockRecorder {
+	return m.recorder
+}
+
+// ProviderQuotas mocks base method.
+func (m *MockQuotaReporter) ProviderQuotas() []enricher.ProviderQuota {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProviderQuotas")
+	ret0, _ := ret[0].([]enricher.ProviderQuota)
+	return ret0
+}
+
+// ProviderQuotas indicates an expected call of ProviderQuotas.
+func (mr *MockQuotaReporterMockRecorder) ProviderQuotas() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProviderQuotas", reflect.TypeOf((*MockQuotaReporter)(nil).ProviderQuotas))
 }
@@ -0,0 +1,251 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/ports/enricher/providers.go
+
+// Package mock_enricher is a generated GoMock package.
+package mock_enricher
+
+import (
+	context "context"
+	reflect "reflect"
+
+	enricher "github.com/Kosodaka/enricher-service/internal/domain/ports/enricher"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAgeProvider is a mock of AgeProvider interface.
+type MockAgeProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockAgeProviderMockRecorder
+}
+
+// MockAgeProviderMockRecorder is the mock recorder for MockAgeProvider.
+type MockAgeProviderMockRecorder struct {
+	mock *MockAgeProvider
+}
+
+// NewMockAgeProvider creates a new mock instance.
+func NewMockAgeProvider(ctrl *gomock.Controller) *MockAgeProvider {
+	mock := &MockAgeProvider{ctrl: ctrl}
+	mock.recorder = &MockAgeProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAgeProvider) EXPECT() *MockAgeProviderMockRecorder {
+	return m.recorder
+}
+
+// Age mocks base method.
+func (m *MockAgeProvider) Age(ctx context.Context, name, countryId string) (*enricher.Age, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Age", ctx, name, countryId)
+	ret0, _ := ret[0].(*enricher.Age)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Age indicates an expected call of Age.
+func (mr *MockAgeProviderMockRecorder) Age(ctx, name, countryId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Age", reflect.TypeOf((*MockAgeProvider)(nil).Age), ctx, name, countryId)
+}
+
+// Ages mocks base method.
+func (m *MockAgeProvider) Ages(ctx context.Context, names []string, countryId string) (map[string]*enricher.Age, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ages", ctx, names, countryId)
+	ret0, _ := ret[0].(map[string]*enricher.Age)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Ages indicates an expected call of Ages.
+func (mr *MockAgeProviderMockRecorder) Ages(ctx, names, countryId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ages", reflect.TypeOf((*MockAgeProvider)(nil).Ages), ctx, names, countryId)
+}
+
+// Name mocks base method.
+func (m *MockAgeProvider) Name() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Name")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Name indicates an expected call of Name.
+func (mr *MockAgeProviderMockRecorder) Name() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Name", reflect.TypeOf((*MockAgeProvider)(nil).Name))
+}
+
+// MockGenderProvider is a mock of GenderProvider interface.
+type MockGenderProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockGenderProviderMockRecorder
+}
+
+// MockGenderProviderMockRecorder is the mock recorder for MockGenderProvider.
+type MockGenderProviderMockRecorder struct {
+	mock *MockGenderProvider
+}
+
+// NewMockGenderProvider creates a new mock instance.
+func NewMockGenderProvider(ctrl *gomock.Controller) *MockGenderProvider {
+	mock := &MockGenderProvider{ctrl: ctrl}
+	mock.recorder = &MockGenderProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGenderProvider) EXPECT() *MockGenderProviderMockRecorder {
+	return m.recorder
+}
+
+// Gender mocks base method.
+func (m *MockGenderProvider) Gender(ctx context.Context, name, countryId string) (*enricher.Gender, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Gender", ctx, name, countryId)
+	ret0, _ := ret[0].(*enricher.Gender)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Gender indicates an expected call of Gender.
+func (mr *MockGenderProviderMockRecorder) Gender(ctx, name, countryId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Gender", reflect.TypeOf((*MockGenderProvider)(nil).Gender), ctx, name, countryId)
+}
+
+// Genders mocks base method.
+func (m *MockGenderProvider) Genders(ctx context.Context, names []string, countryId string) (map[string]*enricher.Gender, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Genders", ctx, names, countryId)
+	ret0, _ := ret[0].(map[string]*enricher.Gender)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Genders indicates an expected call of Genders.
+func (mr *MockGenderProviderMockRecorder) Genders(ctx, names, countryId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Genders", reflect.TypeOf((*MockGenderProvider)(nil).Genders), ctx, names, countryId)
+}
+
+// Name mocks base method.
+func (m *MockGenderProvider) Name() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Name")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Name indicates an expected call of Name.
+func (mr *MockGenderProviderMockRecorder) Name() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Name", reflect.TypeOf((*MockGenderProvider)(nil).Name))
+}
+
+// MockNationalityProvider is a mock of NationalityProvider interface.
+type MockNationalityProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockNationalityProviderMockRecorder
+}
+
+// MockNationalityProviderMockRecorder is the mock recorder for MockNationalityProvider.
+type MockNationalityProviderMockRecorder struct {
+	mock *MockNationalityProvider
+}
+
+// NewMockNationalityProvider creates a new mock instance.
+func NewMockNationalityProvider(ctrl *gomock.Controller) *MockNationalityProvider {
+	mock := &MockNationalityProvider{ctrl: ctrl}
+	mock.recorder = &MockNationalityProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNationalityProvider) EXPECT() *MockNationalityProviderMockRecorder {
+	return m.recorder
+}
+
+// Name mocks base method.
+func (m *MockNationalityProvider) Name() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Name")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Name indicates an expected call of Name.
+func (mr *MockNationalityProviderMockRecorder) Name() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Name", reflect.TypeOf((*MockNationalityProvider)(nil).Name))
+}
+
+// Nationalities mocks base method.
+func (m *MockNationalityProvider) Nationalities(ctx context.Context, names []string) (map[string]*enricher.Nationality, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Nationalities", ctx, names)
+	ret0, _ := ret[0].(map[string]*enricher.Nationality)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Nationalities indicates an expected call of Nationalities.
+func (mr *MockNationalityProviderMockRecorder) Nationalities(ctx, names interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Nationalities", reflect.TypeOf((*MockNationalityProvider)(nil).Nationalities), ctx, names)
+}
+
+// Nationality mocks base method.
+func (m *MockNationalityProvider) Nationality(ctx context.Context, name string) (*enricher.Nationality, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Nationality", ctx, name)
+	ret0, _ := ret[0].(*enricher.Nationality)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Nationality indicates an expected call of Nationality.
+func (mr *MockNationalityProviderMockRecorder) Nationality(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Nationality", reflect.TypeOf((*MockNationalityProvider)(nil).Nationality), ctx, name)
+}
+
+// MockNameRules is a mock of NameRules interface.
+type MockNameRules struct {
+	ctrl     *gomock.Controller
+	recorder *MockNameRulesMockRecorder
+}
+
+// MockNameRulesMockRecorder is the mock recorder for MockNameRules.
+type MockNameRulesMockRecorder struct {
+	mock *MockNameRules
+}
+
+// NewMockNameRules creates a new mock instance.
+func NewMockNameRules(ctrl *gomock.Controller) *MockNameRules {
+	mock := &MockNameRules{ctrl: ctrl}
+	mock.recorder = &MockNameRulesMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNameRules) EXPECT() *MockNameRulesMockRecorder {
+	return m.recorder
+}
+
+// Infer mocks base method.
+func (m *MockNameRules) Infer(surname, patronymic string) enricher.Inference {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Infer", surname, patronymic)
+	ret0, _ := ret[0].(enricher.Inference)
+	return ret0
+}
+
+// Infer indicates an expected call of Infer.
+func (mr *MockNameRulesMockRecorder) Infer(surname, patronymic interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Infer", reflect.TypeOf((*MockNameRules)(nil).Infer), surname, patronymic)
+}
@@ -7,8 +7,11 @@ package mock_repository
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
+	dto "github.com/Kosodaka/enricher-service/internal/domain/dto"
 	model "github.com/Kosodaka/enricher-service/internal/domain/model"
+	enricher "github.com/Kosodaka/enricher-service/internal/domain/ports/enricher"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -65,25 +68,55 @@ func (mr *MockPersonRepositoryMockRecorder) DeletePerson(arg0, arg1 interface{})
 }
 
 // GetPerson mocks base method.
-func (m *MockPersonRepository) GetPerson(arg0 context.Context, arg1 int) (*model.Person, error) {
+func (m *MockPersonRepository) GetPerson(ctx context.Context, id int, includeDeleted bool) (*model.Person, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPerson", arg0, arg1)
+	ret := m.ctrl.Call(m, "GetPerson", ctx, id, includeDeleted)
 	ret0, _ := ret[0].(*model.Person)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetPerson indicates an expected call of GetPerson.
-func (mr *MockPersonRepositoryMockRecorder) GetPerson(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockPersonRepositoryMockRecorder) GetPerson(ctx, id, includeDeleted interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPerson", reflect.TypeOf((*MockPersonRepository)(nil).GetPerson), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPerson", reflect.TypeOf((*MockPersonRepository)(nil).GetPerson), ctx, id, includeDeleted)
+}
+
+// GetPersonAsOf mocks base method.
+func (m *MockPersonRepository) GetPersonAsOf(ctx context.Context, id int, asOf time.Time) (*model.Person, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPersonAsOf", ctx, id, asOf)
+	ret0, _ := ret[0].(*model.Person)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPersonAsOf indicates an expected call of GetPersonAsOf.
+func (mr *MockPersonRepositoryMockRecorder) GetPersonAsOf(ctx, id, asOf interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPersonAsOf", reflect.TypeOf((*MockPersonRepository)(nil).GetPersonAsOf), ctx, id, asOf)
+}
+
+// GetPersonHistory mocks base method.
+func (m *MockPersonRepository) GetPersonHistory(ctx context.Context, id int) ([]model.PersonHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPersonHistory", ctx, id)
+	ret0, _ := ret[0].([]model.PersonHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPersonHistory indicates an expected call of GetPersonHistory.
+func (mr *MockPersonRepositoryMockRecorder) GetPersonHistory(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPersonHistory", reflect.TypeOf((*MockPersonRepository)(nil).GetPersonHistory), ctx, id)
 }
 
 // GetPersons mocks base method.
-func (m *MockPersonRepository) GetPersons(arg0 context.Context, arg1 *model.Person) ([]model.Person, error) {
+func (m *MockPersonRepository) GetPersons(arg0 context.Context, arg1 *dto.PersonFilter) (*dto.PersonPage, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetPersons", arg0, arg1)
-	ret0, _ := ret[0].([]model.Person)
+	ret0, _ := ret[0].(*dto.PersonPage)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -94,6 +127,93 @@ func (mr *MockPersonRepositoryMockRecorder) GetPersons(arg0, arg1 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPersons", reflect.TypeOf((*MockPersonRepository)(nil).GetPersons), arg0, arg1)
 }
 
+// GetPersonsToReview mocks base method.
+func (m *MockPersonRepository) GetPersonsToReview(arg0 context.Context) ([]model.Person, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPersonsToReview", arg0)
+	ret0, _ := ret[0].([]model.Person)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPersonsToReview indicates an expected call of GetPersonsToReview.
+func (mr *MockPersonRepositoryMockRecorder) GetPersonsToReview(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPersonsToReview", reflect.TypeOf((*MockPersonRepository)(nil).GetPersonsToReview), arg0)
+}
+
+// PurgePersons mocks base method.
+func (m *MockPersonRepository) PurgePersons(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgePersons", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgePersons indicates an expected call of PurgePersons.
+func (mr *MockPersonRepositoryMockRecorder) PurgePersons(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgePersons", reflect.TypeOf((*MockPersonRepository)(nil).PurgePersons), ctx, before)
+}
+
+// RestorePerson mocks base method.
+func (m *MockPersonRepository) RestorePerson(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestorePerson", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestorePerson indicates an expected call of RestorePerson.
+func (mr *MockPersonRepositoryMockRecorder) RestorePerson(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestorePerson", reflect.TypeOf((*MockPersonRepository)(nil).RestorePerson), ctx, id)
+}
+
+// SaveReview mocks base method.
+func (m *MockPersonRepository) SaveReview(arg0 context.Context, arg1 *model.Person) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveReview", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveReview indicates an expected call of SaveReview.
+func (mr *MockPersonRepositoryMockRecorder) SaveReview(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveReview", reflect.TypeOf((*MockPersonRepository)(nil).SaveReview), arg0, arg1)
+}
+
+// SearchPersons mocks base method.
+func (m *MockPersonRepository) SearchPersons(ctx context.Context, query string, limit int, includeDeleted bool) ([]dto.PersonMatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchPersons", ctx, query, limit, includeDeleted)
+	ret0, _ := ret[0].([]dto.PersonMatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchPersons indicates an expected call of SearchPersons.
+func (mr *MockPersonRepositoryMockRecorder) SearchPersons(ctx, query, limit, includeDeleted interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchPersons", reflect.TypeOf((*MockPersonRepository)(nil).SearchPersons), ctx, query, limit, includeDeleted)
+}
+
+// UpdateEnrichment mocks base method.
+func (m *MockPersonRepository) UpdateEnrichment(arg0 context.Context, arg1 *model.Person) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateEnrichment", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateEnrichment indicates an expected call of UpdateEnrichment.
+func (mr *MockPersonRepositoryMockRecorder) UpdateEnrichment(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateEnrichment", reflect.TypeOf((*MockPersonRepository)(nil).UpdateEnrichment), arg0, arg1)
+}
+
 // UpdatePerson mocks base method.
 func (m *MockPersonRepository) UpdatePerson(arg0 context.Context, arg1 *model.Person) error {
 	m.ctrl.T.Helper()
@@ -107,3 +227,330 @@ func (mr *MockPersonRepositoryMockRecorder) UpdatePerson(arg0, arg1 interface{})
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePerson", reflect.TypeOf((*MockPersonRepository)(nil).UpdatePerson), arg0, arg1)
 }
+
+// MockEnrichmentCacheRepository is a mock of EnrichmentCacheRepository interface.
+type MockEnrichmentCacheRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockEnrichmentCacheRepositoryMockRecorder
+}
+
+// MockEnrichmentCacheRepositoryMockRecorder is the mock recorder for MockEnrichmentCacheRepository.
+type MockEnrichmentCacheRepositoryMockRecorder struct {
+	mock *MockEnrichmentCacheRepository
+}
+
+// NewMockEnrichmentCacheRepository creates a new mock instance.
+func NewMockEnrichmentCacheRepository(ctrl *gomock.Controller) *MockEnrichmentCacheRepository {
+	mock := &MockEnrichmentCacheRepository{ctrl: ctrl}
+	mock.recorder = &MockEnrichmentCacheRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEnrichmentCacheRepository) EXPECT() *MockEnrichmentCacheRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetEnrichment mocks base method.
+func (m *MockEnrichmentCacheRepository) GetEnrichment(ctx context.Context, name string, notBefore time.Time) (*enricher.EnrichData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEnrichment", ctx, name, notBefore)
+	ret0, _ := ret[0].(*enricher.EnrichData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEnrichment indicates an expected call of GetEnrichment.
+func (mr *MockEnrichmentCacheRepositoryMockRecorder) GetEnrichment(ctx, name, notBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEnrichment", reflect.TypeOf((*MockEnrichmentCacheRepository)(nil).GetEnrichment), ctx, name, notBefore)
+}
+
+// SaveEnrichment mocks base method.
+func (m *MockEnrichmentCacheRepository) SaveEnrichment(ctx context.Context, name string, data *enricher.EnrichData) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveEnrichment", ctx, name, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveEnrichment indicates an expected call of SaveEnrichment.
+func (mr *MockEnrichmentCacheRepositoryMockRecorder) SaveEnrichment(ctx, name, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveEnrichment", reflect.TypeOf((*MockEnrichmentCacheRepository)(nil).SaveEnrichment), ctx, name, data)
+}
+
+// MockJobRepository is a mock of JobRepository interface.
+type MockJobRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockJobRepositoryMockRecorder
+}
+
+// MockJobRepositoryMockRecorder is the mock recorder for MockJobRepository.
+type MockJobRepositoryMockRecorder struct {
+	mock *MockJobRepository
+}
+
+// NewMockJobRepository creates a new mock instance.
+func NewMockJobRepository(ctrl *gomock.Controller) *MockJobRepository {
+	mock := &MockJobRepository{ctrl: ctrl}
+	mock.recorder = &MockJobRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockJobRepository) EXPECT() *MockJobRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddPersonJob mocks base method.
+func (m *MockJobRepository) AddPersonJob(ctx context.Context, person *model.Person, countryId string) (int, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPersonJob", ctx, person, countryId)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// AddPersonJob indicates an expected call of AddPersonJob.
+func (mr *MockJobRepositoryMockRecorder) AddPersonJob(ctx, person, countryId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPersonJob", reflect.TypeOf((*MockJobRepository)(nil).AddPersonJob), ctx, person, countryId)
+}
+
+// AddReenrichRun mocks base method.
+func (m *MockJobRepository) AddReenrichRun(ctx context.Context, filter *dto.ReenrichFilter) (*model.ReenrichRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddReenrichRun", ctx, filter)
+	ret0, _ := ret[0].(*model.ReenrichRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddReenrichRun indicates an expected call of AddReenrichRun.
+func (mr *MockJobRepositoryMockRecorder) AddReenrichRun(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddReenrichRun", reflect.TypeOf((*MockJobRepository)(nil).AddReenrichRun), ctx, filter)
+}
+
+// CancelReenrichRun mocks base method.
+func (m *MockJobRepository) CancelReenrichRun(ctx context.Context, id int) (*model.ReenrichRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelReenrichRun", ctx, id)
+	ret0, _ := ret[0].(*model.ReenrichRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelReenrichRun indicates an expected call of CancelReenrichRun.
+func (mr *MockJobRepositoryMockRecorder) CancelReenrichRun(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelReenrichRun", reflect.TypeOf((*MockJobRepository)(nil).CancelReenrichRun), ctx, id)
+}
+
+// ClaimJob mocks base method.
+func (m *MockJobRepository) ClaimJob(ctx context.Context) (*model.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimJob", ctx)
+	ret0, _ := ret[0].(*model.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimJob indicates an expected call of ClaimJob.
+func (mr *MockJobRepositoryMockRecorder) ClaimJob(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimJob", reflect.TypeOf((*MockJobRepository)(nil).ClaimJob), ctx)
+}
+
+// CompleteJob mocks base method.
+func (m *MockJobRepository) CompleteJob(ctx context.Context, job *model.Job, person *model.Person) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteJob", ctx, job, person)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteJob indicates an expected call of CompleteJob.
+func (mr *MockJobRepositoryMockRecorder) CompleteJob(ctx, job, person interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteJob", reflect.TypeOf((*MockJobRepository)(nil).CompleteJob), ctx, job, person)
+}
+
+// DeferJob mocks base method.
+func (m *MockJobRepository) DeferJob(ctx context.Context, job *model.Job, until time.Time, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeferJob", ctx, job, until, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeferJob indicates an expected call of DeferJob.
+func (mr *MockJobRepositoryMockRecorder) DeferJob(ctx, job, until, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeferJob", reflect.TypeOf((*MockJobRepository)(nil).DeferJob), ctx, job, until, reason)
+}
+
+// FailJob mocks base method.
+func (m *MockJobRepository) FailJob(ctx context.Context, job *model.Job, reason string, retry bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailJob", ctx, job, reason, retry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FailJob indicates an expected call of FailJob.
+func (mr *MockJobRepositoryMockRecorder) FailJob(ctx, job, reason, retry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailJob", reflect.TypeOf((*MockJobRepository)(nil).FailJob), ctx, job, reason, retry)
+}
+
+// GetJob mocks base method.
+func (m *MockJobRepository) GetJob(ctx context.Context, id int) (*model.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJob", ctx, id)
+	ret0, _ := ret[0].(*model.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJob indicates an expected call of GetJob.
+func (mr *MockJobRepositoryMockRecorder) GetJob(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJob", reflect.TypeOf((*MockJobRepository)(nil).GetJob), ctx, id)
+}
+
+// GetReenrichRun mocks base method.
+func (m *MockJobRepository) GetReenrichRun(ctx context.Context, id int) (*model.ReenrichRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReenrichRun", ctx, id)
+	ret0, _ := ret[0].(*model.ReenrichRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReenrichRun indicates an expected call of GetReenrichRun.
+func (mr *MockJobRepositoryMockRecorder) GetReenrichRun(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReenrichRun", reflect.TypeOf((*MockJobRepository)(nil).GetReenrichRun), ctx, id)
+}
+
+// MockProviderUsageRepository is a mock of ProviderUsageRepository interface.
+type MockProviderUsageRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockProviderUsageRepositoryMockRecorder
+}
+
+// MockProviderUsageRepositoryMockRecorder is the mock recorder for MockProviderUsageRepository.
+type MockProviderUsageRepositoryMockRecorder struct {
+	mock *MockProviderUsageRepository
+}
+
+// NewMockProviderUsageRepository creates a new mock instance.
+func NewMockProviderUsageRepository(ctrl *gomock.Controller) *MockProviderUsageRepository {
+	mock := &MockProviderUsageRepository{ctrl: ctrl}
+	mock.recorder = &MockProviderUsageRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProviderUsageRepository) EXPECT() *MockProviderUsageRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetProviderUsage mocks base method.
+func (m *MockProviderUsageRepository) GetProviderUsage(ctx context.Context, day time.Time) ([]enricher.ProviderQuota, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProviderUsage", ctx, day)
+	ret0, _ := ret[0].([]enricher.ProviderQuota)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProviderUsage indicates an expected call of GetProviderUsage.
+func (mr *MockProviderUsageRepositoryMockRecorder) GetProviderUsage(ctx, day interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProviderUsage", reflect.TypeOf((*MockProviderUsageRepository)(nil).GetProviderUsage), ctx, day)
+}
+
+// SaveProviderUsage mocks base method.
+func (m *MockProviderUsageRepository) SaveProviderUsage(ctx context.Context, usage []enricher.ProviderQuota) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveProviderUsage", ctx, usage)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveProviderUsage indicates an expected call of SaveProviderUsage.
+func (mr *MockProviderUsageRepositoryMockRecorder) SaveProviderUsage(ctx, usage interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveProviderUsage", reflect.TypeOf((*MockProviderUsageRepository)(nil).SaveProviderUsage), ctx, usage)
+}
+
+// MockEnrichmentLogRepository is a mock of EnrichmentLogRepository interface.
+type MockEnrichmentLogRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockEnrichmentLogRepositoryMockRecorder
+}
+
+// MockEnrichmentLogRepositoryMockRecorder is the mock recorder for MockEnrichmentLogRepository.
+type MockEnrichmentLogRepositoryMockRecorder struct {
+	mock *MockEnrichmentLogRepository
+}
+
+// NewMockEnrichmentLogRepository creates a new mock instance.
+func NewMockEnrichmentLogRepository(ctrl *gomock.Controller) *MockEnrichmentLogRepository {
+	mock := &MockEnrichmentLogRepository{ctrl: ctrl}
+	mock.recorder = &MockEnrichmentLogRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEnrichmentLogRepository) EXPECT() *MockEnrichmentLogRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddEnrichmentLog mocks base method.
+func (m *MockEnrichmentLogRepository) AddEnrichmentLog(ctx context.Context, personId int, responses []enricher.ProviderResponse) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddEnrichmentLog", ctx, personId, responses)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddEnrichmentLog indicates an expected call of AddEnrichmentLog.
+func (mr *MockEnrichmentLogRepositoryMockRecorder) AddEnrichmentLog(ctx, personId, responses interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddEnrichmentLog", reflect.TypeOf((*MockEnrichmentLogRepository)(nil).AddEnrichmentLog), ctx, personId, responses)
+}
+
+// GetEnrichmentLog mocks base method.
+func (m *MockEnrichmentLogRepository) GetEnrichmentLog(ctx context.Context, personId int) ([]model.EnrichmentLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEnrichmentLog", ctx, personId)
+	ret0, _ := ret[0].([]model.EnrichmentLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEnrichmentLog indicates an expected call of GetEnrichmentLog.
+func (mr *MockEnrichmentLogRepositoryMockRecorder) GetEnrichmentLog(ctx, personId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEnrichmentLog", reflect.TypeOf((*MockEnrichmentLogRepository)(nil).GetEnrichmentLog), ctx, personId)
+}
+
+// PruneEnrichmentLog mocks base method.
+func (m *MockEnrichmentLogRepository) PruneEnrichmentLog(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PruneEnrichmentLog", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PruneEnrichmentLog indicates an expected call of PruneEnrichmentLog.
+func (mr *MockEnrichmentLogRepositoryMockRecorder) PruneEnrichmentLog(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PruneEnrichmentLog", reflect.TypeOf((*MockEnrichmentLogRepository)(nil).PruneEnrichmentLog), ctx, before)
+}
@@ -7,9 +7,11 @@ package mock_service
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	dto "github.com/Kosodaka/enricher-service/internal/domain/dto"
 	model "github.com/Kosodaka/enricher-service/internal/domain/model"
+	enricher "github.com/Kosodaka/enricher-service/internal/domain/ports/enricher"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -51,6 +53,51 @@ func (mr *MockPersonServiceMockRecorder) AddPerson(ctx, data interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPerson", reflect.TypeOf((*MockPersonService)(nil).AddPerson), ctx, data)
 }
 
+// AddPersonAsync mocks base method.
+func (m *MockPersonService) AddPersonAsync(ctx context.Context, data *dto.AddPersonDTO) (int, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPersonAsync", ctx, data)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// AddPersonAsync indicates an expected call of AddPersonAsync.
+func (mr *MockPersonServiceMockRecorder) AddPersonAsync(ctx, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPersonAsync", reflect.TypeOf((*MockPersonService)(nil).AddPersonAsync), ctx, data)
+}
+
+// CancelReenrich mocks base method.
+func (m *MockPersonService) CancelReenrich(ctx context.Context, id int) (*model.ReenrichRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelReenrich", ctx, id)
+	ret0, _ := ret[0].(*model.ReenrichRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelReenrich indicates an expected call of CancelReenrich.
+func (mr *MockPersonServiceMockRecorder) CancelReenrich(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelReenrich", reflect.TypeOf((*MockPersonService)(nil).CancelReenrich), ctx, id)
+}
+
+// CoalescingStats mocks base method.
+func (m *MockPersonService) CoalescingStats(ctx context.Context) enricher.CoalescingStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CoalescingStats", ctx)
+	ret0, _ := ret[0].(enricher.CoalescingStats)
+	return ret0
+}
+
+// CoalescingStats indicates an expected call of CoalescingStats.
+func (mr *MockPersonServiceMockRecorder) CoalescingStats(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CoalescingStats", reflect.TypeOf((*MockPersonService)(nil).CoalescingStats), ctx)
+}
+
 // DeletePerson mocks base method.
 func (m *MockPersonService) DeletePerson(ctx context.Context, id int) error {
 	m.ctrl.T.Helper()
@@ -65,26 +112,86 @@ func (mr *MockPersonServiceMockRecorder) DeletePerson(ctx, id interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePerson", reflect.TypeOf((*MockPersonService)(nil).DeletePerson), ctx, id)
 }
 
+// GetEnrichmentLog mocks base method.
+func (m *MockPersonService) GetEnrichmentLog(ctx context.Context, id int) ([]model.EnrichmentLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEnrichmentLog", ctx, id)
+	ret0, _ := ret[0].([]model.EnrichmentLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEnrichmentLog indicates an expected call of GetEnrichmentLog.
+func (mr *MockPersonServiceMockRecorder) GetEnrichmentLog(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEnrichmentLog", reflect.TypeOf((*MockPersonService)(nil).GetEnrichmentLog), ctx, id)
+}
+
+// GetJob mocks base method.
+func (m *MockPersonService) GetJob(ctx context.Context, id int) (*model.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJob", ctx, id)
+	ret0, _ := ret[0].(*model.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJob indicates an expected call of GetJob.
+func (mr *MockPersonServiceMockRecorder) GetJob(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJob", reflect.TypeOf((*MockPersonService)(nil).GetJob), ctx, id)
+}
+
 // GetPerson mocks base method.
-func (m *MockPersonService) GetPerson(ctx context.Context, id int) (*model.Person, error) {
+func (m *MockPersonService) GetPerson(ctx context.Context, id int, includeDeleted bool) (*model.Person, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPerson", ctx, id)
+	ret := m.ctrl.Call(m, "GetPerson", ctx, id, includeDeleted)
 	ret0, _ := ret[0].(*model.Person)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetPerson indicates an expected call of GetPerson.
-func (mr *MockPersonServiceMockRecorder) GetPerson(ctx, id interface{}) *gomock.Call {
+func (mr *MockPersonServiceMockRecorder) GetPerson(ctx, id, includeDeleted interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPerson", reflect.TypeOf((*MockPersonService)(nil).GetPerson), ctx, id, includeDeleted)
+}
+
+// GetPersonAsOf mocks base method.
+func (m *MockPersonService) GetPersonAsOf(ctx context.Context, id int, asOf time.Time, includeDeleted bool) (*model.Person, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPersonAsOf", ctx, id, asOf, includeDeleted)
+	ret0, _ := ret[0].(*model.Person)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPersonAsOf indicates an expected call of GetPersonAsOf.
+func (mr *MockPersonServiceMockRecorder) GetPersonAsOf(ctx, id, asOf, includeDeleted interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPersonAsOf", reflect.TypeOf((*MockPersonService)(nil).GetPersonAsOf), ctx, id, asOf, includeDeleted)
+}
+
+// GetPersonHistory mocks base method.
+func (m *MockPersonService) GetPersonHistory(ctx context.Context, id int) ([]model.PersonHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPersonHistory", ctx, id)
+	ret0, _ := ret[0].([]model.PersonHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPersonHistory indicates an expected call of GetPersonHistory.
+func (mr *MockPersonServiceMockRecorder) GetPersonHistory(ctx, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPerson", reflect.TypeOf((*MockPersonService)(nil).GetPerson), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPersonHistory", reflect.TypeOf((*MockPersonService)(nil).GetPersonHistory), ctx, id)
 }
 
 // GetPersons mocks base method.
-func (m *MockPersonService) GetPersons(ctx context.Context, data *model.Person) ([]model.Person, error) {
+func (m *MockPersonService) GetPersons(ctx context.Context, data *dto.PersonFilter) (*dto.PersonPage, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetPersons", ctx, data)
-	ret0, _ := ret[0].([]model.Person)
+	ret0, _ := ret[0].(*dto.PersonPage)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -95,6 +202,139 @@ func (mr *MockPersonServiceMockRecorder) GetPersons(ctx, data interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPersons", reflect.TypeOf((*MockPersonService)(nil).GetPersons), ctx, data)
 }
 
+// GetReenrich mocks base method.
+func (m *MockPersonService) GetReenrich(ctx context.Context, id int) (*model.ReenrichRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReenrich", ctx, id)
+	ret0, _ := ret[0].(*model.ReenrichRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReenrich indicates an expected call of GetReenrich.
+func (mr *MockPersonServiceMockRecorder) GetReenrich(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReenrich", reflect.TypeOf((*MockPersonService)(nil).GetReenrich), ctx, id)
+}
+
+// GetReviewQueue mocks base method.
+func (m *MockPersonService) GetReviewQueue(ctx context.Context) ([]model.Person, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReviewQueue", ctx)
+	ret0, _ := ret[0].([]model.Person)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReviewQueue indicates an expected call of GetReviewQueue.
+func (mr *MockPersonServiceMockRecorder) GetReviewQueue(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReviewQueue", reflect.TypeOf((*MockPersonService)(nil).GetReviewQueue), ctx)
+}
+
+// ProviderQuotas mocks base method.
+func (m *MockPersonService) ProviderQuotas(ctx context.Context) []enricher.ProviderQuota {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProviderQuotas", ctx)
+	ret0, _ := ret[0].([]enricher.ProviderQuota)
+	return ret0
+}
+
+// ProviderQuotas indicates an expected call of ProviderQuotas.
+func (mr *MockPersonServiceMockRecorder) ProviderQuotas(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProviderQuotas", reflect.TypeOf((*MockPersonService)(nil).ProviderQuotas), ctx)
+}
+
+// ProviderStatuses mocks base method.
+func (m *MockPersonService) ProviderStatuses(ctx context.Context) []enricher.ProviderStatus {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProviderStatuses", ctx)
+	ret0, _ := ret[0].([]enricher.ProviderStatus)
+	return ret0
+}
+
+// ProviderStatuses indicates an expected call of ProviderStatuses.
+func (mr *MockPersonServiceMockRecorder) ProviderStatuses(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProviderStatuses", reflect.TypeOf((*MockPersonService)(nil).ProviderStatuses), ctx)
+}
+
+// ReenrichPerson mocks base method.
+func (m *MockPersonService) ReenrichPerson(ctx context.Context, id int) (*model.Person, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReenrichPerson", ctx, id)
+	ret0, _ := ret[0].(*model.Person)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReenrichPerson indicates an expected call of ReenrichPerson.
+func (mr *MockPersonServiceMockRecorder) ReenrichPerson(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReenrichPerson", reflect.TypeOf((*MockPersonService)(nil).ReenrichPerson), ctx, id)
+}
+
+// RestorePerson mocks base method.
+func (m *MockPersonService) RestorePerson(ctx context.Context, id int) (*model.Person, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestorePerson", ctx, id)
+	ret0, _ := ret[0].(*model.Person)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestorePerson indicates an expected call of RestorePerson.
+func (mr *MockPersonServiceMockRecorder) RestorePerson(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestorePerson", reflect.TypeOf((*MockPersonService)(nil).RestorePerson), ctx, id)
+}
+
+// ReviewPerson mocks base method.
+func (m *MockPersonService) ReviewPerson(ctx context.Context, id int, data *dto.ReviewDTO) (*model.Person, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReviewPerson", ctx, id, data)
+	ret0, _ := ret[0].(*model.Person)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReviewPerson indicates an expected call of ReviewPerson.
+func (mr *MockPersonServiceMockRecorder) ReviewPerson(ctx, id, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReviewPerson", reflect.TypeOf((*MockPersonService)(nil).ReviewPerson), ctx, id, data)
+}
+
+// SearchPersons mocks base method.
+func (m *MockPersonService) SearchPersons(ctx context.Context, query string, limit int, includeDeleted bool) ([]dto.PersonMatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchPersons", ctx, query, limit, includeDeleted)
+	ret0, _ := ret[0].([]dto.PersonMatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchPersons indicates an expected call of SearchPersons.
+func (mr *MockPersonServiceMockRecorder) SearchPersons(ctx, query, limit, includeDeleted interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchPersons", reflect.TypeOf((*MockPersonService)(nil).SearchPersons), ctx, query, limit, includeDeleted)
+}
+
+// StartReenrich mocks base method.
+func (m *MockPersonService) StartReenrich(ctx context.Context, filter *dto.ReenrichFilter) (*model.ReenrichRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartReenrich", ctx, filter)
+	ret0, _ := ret[0].(*model.ReenrichRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartReenrich indicates an expected call of StartReenrich.
+func (mr *MockPersonServiceMockRecorder) StartReenrich(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartReenrich", reflect.TypeOf((*MockPersonService)(nil).StartReenrich), ctx, filter)
+}
+
 // UpdatePerson mocks base method.
 func (m *MockPersonService) UpdatePerson(ctx context.Context, data *model.Person) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,9 @@
+package names
+
+import "strings"
+
+// Normalize returns the canonical form of a name used as a lookup key,
+// so "Ivan", " ivan" and "IVAN" share one cache entry.
+func Normalize(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
@@ -0,0 +1,47 @@
+package names
+
+import (
+	"strings"
+	"unicode"
+)
+
+// cyrillic follows the passport and BGN/PCGN spellings rather than GOST 7.79 or ISO 9.
+// The providers count names as people spell them in their documents, such as Mikhail,
+// Aleksey and Yuliya, and know next to nothing about Mixail or Aleksej. The signs ъ and ь
+// are left out. A letter becomes at most four, щ is shch.
+var cyrillic = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya",
+	// Ukrainian and Belarusian letters.
+	'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g", 'ў': "u",
+}
+
+// Transliterate returns the Latin form of a Cyrillic name. Other letters, hyphens and
+// apostrophes are kept as they are, so Latin names come back unchanged.
+func Transliterate(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		latin, ok := cyrillic[unicode.ToLower(r)]
+		if !ok {
+			b.WriteRune(r)
+			continue
+		}
+		if unicode.IsUpper(r) && latin != "" {
+			latin = capitalize(latin, i+1 < len(runes) && unicode.IsUpper(runes[i+1]))
+		}
+		b.WriteString(latin)
+	}
+	return b.String()
+}
+
+// capitalize upper-cases the first letter of latin, or all of it inside an upper-case word.
+func capitalize(latin string, upperWord bool) string {
+	if upperWord {
+		return strings.ToUpper(latin)
+	}
+	return strings.ToUpper(latin[:1]) + latin[1:]
+}
@@ -0,0 +1,37 @@
+package names
+
+import "testing"
+
+func TestTransliterate(t *testing.T) {
+	cases := []struct {
+		name string
+		want string
+	}{
+		{name: "Алексей", want: "Aleksey"},
+		{name: "Михаил", want: "Mikhail"},
+		{name: "Андрей", want: "Andrey"},
+		{name: "Сергей", want: "Sergey"},
+		{name: "Фёдор", want: "Fyodor"},
+		{name: "Наталья", want: "Natalya"},
+		{name: "Ксения", want: "Kseniya"},
+		{name: "Юлия", want: "Yuliya"},
+		{name: "Хабаров", want: "Khabarov"},
+		{name: "Щукин", want: "Shchukin"},
+		{name: "Чайковский", want: "Chaykovskiy"},
+		{name: "Цветаева", want: "Tsvetaeva"},
+		{name: "Лицина", want: "Litsina"},
+		{name: "Жанна-Мария", want: "Zhanna-Mariya"},
+		{name: "ЖУКОВ", want: "ZHUKOV"},
+		{name: "Подъячев", want: "Podyachev"},
+		{name: "Олесь", want: "Oles"},
+		{name: "O'Brien", want: "O'Brien"},
+		{name: "Zoë", want: "Zoë"},
+	}
+	for _, testCases := range cases {
+		t.Run(testCases.name, func(t *testing.T) {
+			if got := Transliterate(testCases.name); got != testCases.want {
+				t.Errorf("got %q, want %q", got, testCases.want)
+			}
+		})
+	}
+}
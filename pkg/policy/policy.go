@@ -0,0 +1,102 @@
+package policy
+
+import (
+	"fmt"
+	"github.com/Kosodaka/enricher-service/internal/domain/model"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// fields maps the attribute fields a condition may test to their value on a person.
+// ok is false when the attribute is missing or verified by hand, such a condition never holds.
+var fields = map[string]func(p *model.Person) (value float64, ok bool){
+	"age": func(p *model.Person) (float64, bool) {
+		if p.Age == nil || p.AgeVerified {
+			return 0, false
+		}
+		return float64(*p.Age), true
+	},
+	"age.count": func(p *model.Person) (float64, bool) {
+		return float64(p.AgeCount), p.Age != nil && !p.AgeVerified
+	},
+	"gender.probability": func(p *model.Person) (float64, bool) {
+		return p.GenderProbability, p.Gender != nil && !p.GenderVerified
+	},
+	"gender.count": func(p *model.Person) (float64, bool) {
+		return float64(p.GenderCount), p.Gender != nil && !p.GenderVerified
+	},
+	"nationality.probability": func(p *model.Person) (float64, bool) {
+		return p.NationalityProbability, p.Nationality != nil && !p.NationalityVerified
+	},
+	"nationality.count": func(p *model.Person) (float64, bool) {
+		return float64(p.NationalityCount), p.Nationality != nil && !p.NationalityVerified
+	},
+}
+
+var operators = map[string]func(a, b float64) bool{
+	"<":  func(a, b float64) bool { return a < b },
+	"<=": func(a, b float64) bool { return a <= b },
+	">":  func(a, b float64) bool { return a > b },
+	">=": func(a, b float64) bool { return a >= b },
+	"==": func(a, b float64) bool { return a == b },
+	"!=": func(a, b float64) bool { return a != b },
+}
+
+var conditionPattern = regexp.MustCompile(`^\s*([a-z.]+)\s*([<>=!]=?)\s*(\S+)\s*$`)
+
+// condition compares one attribute field with a constant, e.g. gender.probability < 0.7.
+type condition struct {
+	field string
+	op    string
+	value float64
+}
+
+func (c condition) String() string {
+	return c.field + " " + c.op + " " + strconv.FormatFloat(c.value, 'f', -1, 64)
+}
+
+// Policy sends a person to review when any of its conditions holds.
+type Policy struct {
+	conditions []condition
+}
+
+// Parse reads comma separated conditions such as "gender.probability < 0.7, nationality.probability < 0.3".
+// An empty expression gives a policy that never asks for review.
+func Parse(expr string) (*Policy, error) {
+	p := &Policy{}
+	for _, part := range strings.Split(expr, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		tokens := conditionPattern.FindStringSubmatch(part)
+		if tokens == nil {
+			return nil, fmt.Errorf("policy condition %q: want <field> <operator> <number>", part)
+		}
+		tokens = tokens[1:]
+		if _, ok := fields[tokens[0]]; !ok {
+			return nil, fmt.Errorf("policy condition %q: unknown field %s", part, tokens[0])
+		}
+		if _, ok := operators[tokens[1]]; !ok {
+			return nil, fmt.Errorf("policy condition %q: unknown operator %s", part, tokens[1])
+		}
+		value, err := strconv.ParseFloat(tokens[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("policy condition %q: %w", part, err)
+		}
+		p.conditions = append(p.conditions, condition{field: tokens[0], op: tokens[1], value: value})
+	}
+	return p, nil
+}
+
+// Review returns the conditions the person matches, none when it needs no review.
+func (p *Policy) Review(person *model.Person) []string {
+	var reasons []string
+	for _, c := range p.conditions {
+		value, ok := fields[c.field](person)
+		if ok && operators[c.op](value, c.value) {
+			reasons = append(reasons, c.String())
+		}
+	}
+	return reasons
+}
@@ -0,0 +1,77 @@
+package policy
+
+import (
+	"github.com/Kosodaka/enricher-service/internal/domain/model"
+	"github.com/Kosodaka/enricher-service/pkg/ptr"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		name    string
+		expr    string
+		wantErr bool
+	}{
+		{name: "empty", expr: ""},
+		{name: "several conditions", expr: "gender.probability < 0.7, nationality.probability<= 0.3 ,age.count >= 10"},
+		{name: "unknown field", expr: "height < 2", wantErr: true},
+		{name: "unknown operator", expr: "age ~ 2", wantErr: true},
+		{name: "not a number", expr: "age < old", wantErr: true},
+		{name: "no spaces", expr: "age<2"},
+		{name: "missing number", expr: "age <", wantErr: true},
+	}
+	for _, testCases := range cases {
+		t.Run(testCases.name, func(t *testing.T) {
+			_, err := Parse(testCases.expr)
+			if (err != nil) != testCases.wantErr {
+				t.Errorf("got error %v, want error %v", err, testCases.wantErr)
+			}
+		})
+	}
+}
+
+func TestPolicy_Review(t *testing.T) {
+	p, err := Parse("gender.probability < 0.7, nationality.probability < 0.3, age > 100")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name   string
+		person *model.Person
+		want   []string
+	}{
+		{
+			name: "confident predictions",
+			person: &model.Person{
+				Age: ptr.To(40), Gender: ptr.To("male"), GenderProbability: 0.98,
+				Nationality: ptr.To("RU"), NationalityProbability: 0.5,
+			},
+		}, {
+			name: "doubtful predictions",
+			person: &model.Person{
+				Age: ptr.To(120), Gender: ptr.To("male"), GenderProbability: 0.55,
+				Nationality: ptr.To("RU"), NationalityProbability: 0.1,
+			},
+			want: []string{"gender.probability < 0.7", "nationality.probability < 0.3", "age > 100"},
+		}, {
+			name: "missing attributes are not doubted",
+			person: &model.Person{
+				Gender: ptr.To("female"), GenderProbability: 0.6,
+			},
+			want: []string{"gender.probability < 0.7"},
+		}, {
+			name: "verified attributes are not doubted",
+			person: &model.Person{
+				Gender: ptr.To("female"), GenderProbability: 0.6, GenderVerified: true,
+			},
+		},
+	}
+	for _, testCases := range cases {
+		t.Run(testCases.name, func(t *testing.T) {
+			if got := p.Review(testCases.person); !reflect.DeepEqual(got, testCases.want) {
+				t.Errorf("got %v, want %v", got, testCases.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,6 @@
+package ptr
+
+// To returns a pointer to a copy of v, handy for filling nullable fields.
+func To[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,58 @@
+package trigram
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Threshold is the word similarity from which pg_trgm considers a string to match.
+const Threshold = 0.6
+
+// Trigrams splits s the way pg_trgm does: into lowercase words of letters and digits,
+// each padded with two spaces in front and one behind. The trigrams keep their order.
+func Trigrams(s string) []string {
+	var trigrams []string
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			trigrams = append(trigrams, string(padded[i:i+3]))
+		}
+	}
+	return trigrams
+}
+
+// WordSimilarity is the greatest similarity between the trigrams of query and any
+// continuous extent of the trigrams of s, as word_similarity of pg_trgm. It ranges
+// from 0 to 1, so a query matching a whole word of s scores 1.
+func WordSimilarity(query, s string) float64 {
+	wanted := map[string]bool{}
+	for _, t := range Trigrams(query) {
+		wanted[t] = true
+	}
+	if len(wanted) == 0 {
+		return 0
+	}
+
+	trigrams := Trigrams(s)
+	best := 0.0
+	for i := range trigrams {
+		seen := map[string]bool{}
+		common := 0
+		for _, t := range trigrams[i:] {
+			if seen[t] {
+				continue
+			}
+			seen[t] = true
+			if wanted[t] {
+				common++
+			}
+			if sml := float64(common) / float64(len(wanted)+len(seen)-common); sml > best {
+				best = sml
+			}
+		}
+	}
+	return best
+}
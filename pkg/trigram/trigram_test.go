@@ -0,0 +1,30 @@
+package trigram
+
+import (
+	"math"
+	"testing"
+)
+
+func TestWordSimilarity(t *testing.T) {
+	cases := []struct {
+		name  string
+		query string
+		s     string
+		want  float64
+	}{
+		{name: "pg_trgm example", query: "word", s: "two words", want: 0.8},
+		{name: "exact word", query: "Dementiev", s: "Ivan Dementiev Petrovich", want: 1},
+		{name: "mistyped surname", query: "dementev", s: "Ivan Dementiev Petrovich", want: 2.0 / 3},
+		{name: "cyrillic", query: "Дементьев", s: "Иван Дементьев", want: 1},
+		{name: "unrelated", query: "Smith", s: "Ivan Dementiev", want: 0},
+		{name: "empty query", query: " ", s: "Ivan", want: 0},
+	}
+	for _, testCases := range cases {
+		t.Run(testCases.name, func(t *testing.T) {
+			got := WordSimilarity(testCases.query, testCases.s)
+			if math.Abs(got-testCases.want) > 1e-9 {
+				t.Errorf("got %v, want %v", got, testCases.want)
+			}
+		})
+	}
+}
@@ -8,6 +8,14 @@ import (
 	"regexp"
 )
 
+// namePart is a capitalized word of any script, optionally with a Mc or Mac prefix.
+const namePart = `(?:Mc|Mac)?\p{Lu}[\p{Ll}\p{M}]*`
+
+// namePattern matches names such as "Алексей", "O'Brien", "Anne-Marie", "McDonald" and
+// "d'Artagnan": capitalized parts joined by hyphens or apostrophes, with an optional
+// lowercase particle in front.
+var namePattern = regexp.MustCompile(`^(?:\p{Ll}{1,3}['’])?` + namePart + `(?:[-'’]` + namePart + `)*$`)
+
 type Validator struct {
 }
 
@@ -24,28 +32,60 @@ func (Validator) ValidateId(id int) error {
 
 func (Validator) ValidateDataToAdd(data *dto.AddPersonDTO) error {
 	return validation.ValidateStruct(data,
-		validation.Field(&data.Name, validation.Required, validation.Match(regexp.MustCompile(`^[A-Z][a-z]+$`))),
-		validation.Field(&data.Surname, validation.Required, validation.Match(regexp.MustCompile(`^[A-Z][a-z]+$`))),
-		validation.Field(&data.Patronymic, validation.Match(regexp.MustCompile(`^[A-Z][a-z]+$`))),
+		validation.Field(&data.Name, validation.Required, validation.Match(namePattern)),
+		validation.Field(&data.Surname, validation.Required, validation.Match(namePattern)),
+		validation.Field(&data.Patronymic, validation.Match(namePattern)),
+		validation.Field(&data.CountryId, validation.Match(regexp.MustCompile(`^[A-Z]{2}$`))),
+		validation.Field(&data.Age, validation.Min(0), validation.Max(150)),
+		validation.Field(&data.Gender, validation.In("female", "male")),
+		validation.Field(&data.Nationality, validation.Match(regexp.MustCompile(`^[A-Z]{2}$`))),
 	)
 }
 
 func (Validator) ValidateDataToGet(data *model.Person) error {
 	return validation.ValidateStruct(data,
-		validation.Field(&data.Name, validation.Match(regexp.MustCompile(`^[A-Z][a-z]+$`))),
-		validation.Field(&data.Surname, validation.Match(regexp.MustCompile(`^[A-Z][a-z]+$`))),
-		validation.Field(&data.Patronymic, validation.Match(regexp.MustCompile(`^[A-Z][a-z]+$`))),
+		validation.Field(&data.Name, validation.Match(namePattern)),
+		validation.Field(&data.Surname, validation.Match(namePattern)),
+		validation.Field(&data.Patronymic, validation.Match(namePattern)),
 		validation.Field(&data.Nationality, validation.Match(regexp.MustCompile(`^[A-Z]{2}$`))),
 		validation.Field(&data.Gender, validation.In("female", "male")),
 	)
 }
+
+// ValidatePersonFilter validates the person fields of the filter, its nationalities,
+// the age range and the page size, an unset page size is left to the default.
+func (v Validator) ValidatePersonFilter(data *dto.PersonFilter) error {
+	if err := v.ValidateDataToGet(&data.Person); err != nil {
+		return err
+	}
+	if err := validation.ValidateStruct(data,
+		validation.Field(&data.Nationalities, validation.Each(validation.Match(regexp.MustCompile(`^[A-Z]{2}$`)))),
+		validation.Field(&data.AgeGte, validation.Min(0)),
+		validation.Field(&data.AgeLte, validation.Min(0)),
+		validation.Field(&data.Limit, validation.Min(1), validation.Max(dto.MaxPersonLimit)),
+	); err != nil {
+		return err
+	}
+	if data.AgeGte != nil && data.AgeLte != nil && *data.AgeGte > *data.AgeLte {
+		return validation.Errors{"age_lte": errors.New("must be no less than age_gte")}
+	}
+	return nil
+}
 func (Validator) ValidateDataToUpdate(data *model.Person) error {
 	return validation.ValidateStruct(data,
-		validation.Field(&data.Name, validation.Required, validation.Match(regexp.MustCompile(`^[A-Z][a-z]+$`))),
-		validation.Field(&data.Surname, validation.Required, validation.Match(regexp.MustCompile(`^[A-Z][a-z]+$`))),
-		validation.Field(&data.Patronymic, validation.Match(regexp.MustCompile(`^[A-Z][a-z]+$`))),
+		validation.Field(&data.Name, validation.Required, validation.Match(namePattern)),
+		validation.Field(&data.Surname, validation.Required, validation.Match(namePattern)),
+		validation.Field(&data.Patronymic, validation.Match(namePattern)),
 		validation.Field(&data.Nationality, validation.Required, validation.Match(regexp.MustCompile(`^[A-Z]{2}$`))),
 		validation.Field(&data.Age, validation.Required),
 		validation.Field(&data.Gender, validation.Required, validation.In("female", "male")),
 	)
 }
+
+func (Validator) ValidateReview(data *dto.ReviewDTO) error {
+	return validation.ValidateStruct(data,
+		validation.Field(&data.Age, validation.Min(0), validation.Max(150)),
+		validation.Field(&data.Gender, validation.In("female", "male")),
+		validation.Field(&data.Nationality, validation.Match(regexp.MustCompile(`^[A-Z]{2}$`))),
+	)
+}
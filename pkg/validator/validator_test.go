@@ -1,9 +1,11 @@
 package validator
 
 import (
+	"errors"
 	"github.com/Kosodaka/enricher-service/internal/domain/dto"
 	domainErr "github.com/Kosodaka/enricher-service/internal/domain/errors"
 	"github.com/Kosodaka/enricher-service/internal/domain/model"
+	"github.com/Kosodaka/enricher-service/pkg/ptr"
 	validation "github.com/go-ozzo/ozzo-validation"
 	"reflect"
 	"testing"
@@ -35,6 +37,57 @@ func TestValidateDataToAdd(t *testing.T) {
 			},
 			expErr: nil,
 		},
+		{
+			name: "valid_with_country",
+			data: &dto.AddPersonDTO{
+				Name:      "Dmitriy",
+				Surname:   "Ushakov",
+				CountryId: "RU",
+			},
+			expErr: nil,
+		},
+		{
+			name: "valid_unicode_names",
+			data: &dto.AddPersonDTO{
+				Name:       "Анна-Мария",
+				Surname:    "O'Brien",
+				Patronymic: "Алексеевна",
+			},
+			expErr: nil,
+		},
+		{
+			name: "valid_prefixed_surnames",
+			data: &dto.AddPersonDTO{
+				Name:    "Charles",
+				Surname: "d'Artagnan-McDonald",
+			},
+			expErr: nil,
+		},
+		{
+			name: "invalid_name_digits",
+			data: &dto.AddPersonDTO{
+				Name:    "Ivan3",
+				Surname: "Ushakov",
+			},
+			expErr: validation.Errors{"name": domainErr.InvalidData},
+		},
+		{
+			name: "invalid_name_dangling_hyphen",
+			data: &dto.AddPersonDTO{
+				Name:    "Anne-",
+				Surname: "Ushakov",
+			},
+			expErr: validation.Errors{"name": domainErr.InvalidData},
+		},
+		{
+			name: "invalid_country",
+			data: &dto.AddPersonDTO{
+				Name:      "Dmitriy",
+				Surname:   "Ushakov",
+				CountryId: "Russia",
+			},
+			expErr: validation.Errors{"country_id": domainErr.InvalidData},
+		},
 		{
 			name: "invalid_name_lowercase",
 			data: &dto.AddPersonDTO{
@@ -83,6 +136,26 @@ func TestValidateDataToAdd(t *testing.T) {
 			},
 			expErr: validation.Errors{"surname": domainErr.EmptyField},
 		},
+		{
+			name: "valid_known_attributes",
+			data: &dto.AddPersonDTO{
+				Name:        "Dmitriy",
+				Surname:     "Ushakov",
+				Age:         ptr.To(34),
+				Gender:      ptr.To("male"),
+				Nationality: ptr.To("RU"),
+			},
+			expErr: nil,
+		},
+		{
+			name: "invalid_known_gender",
+			data: &dto.AddPersonDTO{
+				Name:    "Dmitriy",
+				Surname: "Ushakov",
+				Gender:  ptr.To("m"),
+			},
+			expErr: validation.Errors{"gender": domainErr.InvalidGender},
+		},
 	}
 
 	for _, testCases := range cases {
@@ -155,14 +228,14 @@ func TestValidateDataToGet(t *testing.T) {
 		{
 			name: "invalid_nationality_three_chars",
 			data: &model.Person{
-				Nationality: "USA",
+				Nationality: ptr.To("USA"),
 			},
 			expErr: validation.Errors{"nationality": domainErr.InvalidData},
 		},
 		{
 			name: "invalid_gender_uppercase",
 			data: &model.Person{
-				Gender: "Female",
+				Gender: ptr.To("Female"),
 			},
 			expErr: validation.Errors{"gender": domainErr.InvalidGender},
 		},
@@ -192,9 +265,9 @@ func TestValidateDataToUpdate(t *testing.T) {
 				Name:        "Dmitriy",
 				Surname:     "Ushakov",
 				Patronymic:  "Vasilevich",
-				Age:         34,
-				Gender:      "male",
-				Nationality: "UA",
+				Age:         ptr.To(34),
+				Gender:      ptr.To("male"),
+				Nationality: ptr.To("UA"),
 			},
 			expErr: nil,
 		},
@@ -204,9 +277,9 @@ func TestValidateDataToUpdate(t *testing.T) {
 				Name:        "Dmitriy",
 				Surname:     "Ushakov",
 				Patronymic:  "",
-				Age:         34,
-				Gender:      "male",
-				Nationality: "UA",
+				Age:         ptr.To(34),
+				Gender:      ptr.To("male"),
+				Nationality: ptr.To("UA"),
 			},
 			expErr: nil,
 		},
@@ -215,9 +288,9 @@ func TestValidateDataToUpdate(t *testing.T) {
 			data: &model.Person{
 				Name:        "dmitriy",
 				Surname:     "Ushakov",
-				Age:         34,
-				Gender:      "male",
-				Nationality: "UA",
+				Age:         ptr.To(34),
+				Gender:      ptr.To("male"),
+				Nationality: ptr.To("UA"),
 			},
 			expErr: validation.Errors{"name": domainErr.InvalidData},
 		},
@@ -226,9 +299,9 @@ func TestValidateDataToUpdate(t *testing.T) {
 			data: &model.Person{
 				Name:        "DmitRiy",
 				Surname:     "Ushakov",
-				Age:         34,
-				Gender:      "male",
-				Nationality: "UA",
+				Age:         ptr.To(34),
+				Gender:      ptr.To("male"),
+				Nationality: ptr.To("UA"),
 			},
 			expErr: validation.Errors{"name": domainErr.InvalidData},
 		},
@@ -237,9 +310,9 @@ func TestValidateDataToUpdate(t *testing.T) {
 			data: &model.Person{
 				Name:        "",
 				Surname:     "Ushakov",
-				Age:         34,
-				Gender:      "male",
-				Nationality: "UA",
+				Age:         ptr.To(34),
+				Gender:      ptr.To("male"),
+				Nationality: ptr.To("UA"),
 			},
 			expErr: validation.Errors{"name": domainErr.EmptyField},
 		},
@@ -248,9 +321,9 @@ func TestValidateDataToUpdate(t *testing.T) {
 			data: &model.Person{
 				Name:        "Dmitriy",
 				Surname:     "ushakov",
-				Age:         34,
-				Gender:      "male",
-				Nationality: "UA",
+				Age:         ptr.To(34),
+				Gender:      ptr.To("male"),
+				Nationality: ptr.To("UA"),
 			},
 			expErr: validation.Errors{"surname": domainErr.InvalidData},
 		},
@@ -259,9 +332,9 @@ func TestValidateDataToUpdate(t *testing.T) {
 			data: &model.Person{
 				Name:        "Dmitriy",
 				Surname:     "UsHakov",
-				Age:         34,
-				Gender:      "male",
-				Nationality: "UA",
+				Age:         ptr.To(34),
+				Gender:      ptr.To("male"),
+				Nationality: ptr.To("UA"),
 			},
 			expErr: validation.Errors{"surname": domainErr.InvalidData},
 		},
@@ -270,9 +343,9 @@ func TestValidateDataToUpdate(t *testing.T) {
 			data: &model.Person{
 				Name:        "Dmitriy",
 				Surname:     "",
-				Age:         34,
-				Gender:      "male",
-				Nationality: "UA",
+				Age:         ptr.To(34),
+				Gender:      ptr.To("male"),
+				Nationality: ptr.To("UA"),
 			},
 			expErr: validation.Errors{"surname": domainErr.EmptyField},
 		},
@@ -281,9 +354,9 @@ func TestValidateDataToUpdate(t *testing.T) {
 			data: &model.Person{
 				Name:        "Dmitriy",
 				Surname:     "Ushakov",
-				Age:         34,
-				Gender:      "male",
-				Nationality: "USA",
+				Age:         ptr.To(34),
+				Gender:      ptr.To("male"),
+				Nationality: ptr.To("USA"),
 			},
 			expErr: validation.Errors{"nationality": domainErr.InvalidData},
 		},
@@ -292,9 +365,9 @@ func TestValidateDataToUpdate(t *testing.T) {
 			data: &model.Person{
 				Name:        "Dmitriy",
 				Surname:     "Ushakov",
-				Age:         34,
-				Gender:      "Male",
-				Nationality: "UA",
+				Age:         ptr.To(34),
+				Gender:      ptr.To("Male"),
+				Nationality: ptr.To("UA"),
 			},
 			expErr: validation.Errors{"gender": domainErr.InvalidGender},
 		},
@@ -309,3 +382,102 @@ func TestValidateDataToUpdate(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateReview(t *testing.T) {
+	validator := NewValidator()
+
+	cases := []struct {
+		name   string
+		data   *dto.ReviewDTO
+		expErr error
+	}{
+		{
+			name:   "valid_confirmation",
+			data:   &dto.ReviewDTO{},
+			expErr: nil,
+		},
+		{
+			name: "valid_correction",
+			data: &dto.ReviewDTO{
+				Age:         ptr.To(0),
+				Gender:      ptr.To("female"),
+				Nationality: ptr.To("KZ"),
+			},
+			expErr: nil,
+		},
+		{
+			name:   "invalid_negative_age",
+			data:   &dto.ReviewDTO{Age: ptr.To(-1)},
+			expErr: validation.Errors{"age": errors.New("must be no less than 0")},
+		},
+		{
+			name:   "invalid_gender",
+			data:   &dto.ReviewDTO{Gender: ptr.To("other")},
+			expErr: validation.Errors{"gender": domainErr.InvalidGender},
+		},
+		{
+			name:   "invalid_nationality_lowercase",
+			data:   &dto.ReviewDTO{Nationality: ptr.To("kz")},
+			expErr: validation.Errors{"nationality": domainErr.InvalidData},
+		},
+	}
+
+	for _, testCases := range cases {
+		t.Run(testCases.name, func(t *testing.T) {
+			err := validator.ValidateReview(testCases.data)
+			if !reflect.DeepEqual(err, testCases.expErr) {
+				t.Errorf("got %v, want %v", err, testCases.expErr)
+			}
+		})
+	}
+}
+
+func TestValidatePersonFilter(t *testing.T) {
+	validator := NewValidator()
+
+	cases := []struct {
+		name   string
+		data   *dto.PersonFilter
+		expErr error
+	}{
+		{
+			name: "valid_filter",
+			data: &dto.PersonFilter{
+				Nationalities: []string{"RU", "UA"},
+				AgeGte:        ptr.To(20),
+				AgeLte:        ptr.To(20),
+				Limit:         dto.MaxPersonLimit,
+			},
+			expErr: nil,
+		},
+		{
+			name:   "invalid_name_lowercase",
+			data:   &dto.PersonFilter{Person: model.Person{Name: "dmitriy"}},
+			expErr: validation.Errors{"name": domainErr.InvalidData},
+		},
+		{
+			name:   "invalid_nationality",
+			data:   &dto.PersonFilter{Nationalities: []string{"RU", "USA"}},
+			expErr: validation.Errors{"nationality": validation.Errors{"1": domainErr.InvalidData}},
+		},
+		{
+			name:   "invalid_age_range",
+			data:   &dto.PersonFilter{AgeGte: ptr.To(40), AgeLte: ptr.To(20)},
+			expErr: validation.Errors{"age_lte": errors.New("must be no less than age_gte")},
+		},
+		{
+			name:   "invalid_limit",
+			data:   &dto.PersonFilter{Limit: dto.MaxPersonLimit + 1},
+			expErr: validation.Errors{"limit": errors.New("must be no greater than 1000")},
+		},
+	}
+
+	for _, testCases := range cases {
+		t.Run(testCases.name, func(t *testing.T) {
+			err := validator.ValidatePersonFilter(testCases.data)
+			if !reflect.DeepEqual(err, testCases.expErr) {
+				t.Errorf("got %v, want %v", err, testCases.expErr)
+			}
+		})
+	}
+}
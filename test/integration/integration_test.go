@@ -53,7 +53,10 @@ func (s *TestSuite) SetupSuite() {
 	if err != nil {
 		panic(err)
 	}
-	enricher := enricher.NewEnricher(cfg)
+	enricher, err := enricher.NewEnricher(cfg)
+	if err != nil {
+		panic(err)
+	}
 	personRepository := repository.NewPersonPostgres(db)
 	personService := service.NewService()
 	personService.Init(service.SetRepository(personRepository), service.SetEnricher(enricher), service.SetLogger(logger), service.SetValidator(valid))